@@ -1,7 +1,5 @@
 package goiter
 
-import "iter"
-
 // Count counts the number of elements yielded by the input iterator.
 func Count[TIter SeqX[T], T any](iterator TIter) int {
     count := 0
@@ -49,16 +47,8 @@ func Scan[TIter SeqX[T], TAcc any, T any](
     folder func(TAcc, T) TAcc,
 ) Iterator[TAcc] {
     return func(yield func(TAcc) bool) {
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-
         acc := init
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
-
+        for v := range iterator {
             acc = folder(acc, v)
             if !yield(acc) {
                 return
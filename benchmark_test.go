@@ -0,0 +1,448 @@
+package goiter
+
+import (
+    "cmp"
+    "io"
+    "iter"
+    "log/slog"
+    "math/rand/v2"
+    "strconv"
+    "testing"
+    "time"
+)
+
+// benchSize is the number of values yielded by the input iterators of the benchmarks.
+const benchSize = 1000
+
+func benchSource() Iterator[int] {
+    return Range(1, benchSize)
+}
+
+func benchSource2() Iterator2[int, int] {
+    return Transform12(benchSource(), func(v int) (int, int) { return v, v })
+}
+
+// benchmark iterates over the iterator built by newIterator b.N times, so building the iterator is measured as well.
+func benchmark[T any](b *testing.B, newIterator func() Iterator[T]) {
+    b.ReportAllocs()
+    for range b.N {
+        for v := range newIterator() {
+            _ = v
+        }
+    }
+}
+
+func benchmark2[T1, T2 any](b *testing.B, newIterator func() Iterator2[T1, T2]) {
+    b.ReportAllocs()
+    for range b.N {
+        for v1, v2 := range newIterator() {
+            _, _ = v1, v2
+        }
+    }
+}
+
+// BenchmarkBaselinePush and BenchmarkBaselinePull show the cost of ranging over the source directly and through iter.Pull,
+// which is what every operator paid before most of them switched to push iteration.
+func BenchmarkBaselinePush(b *testing.B) {
+    benchmark(b, benchSource)
+}
+
+func BenchmarkBaselinePull(b *testing.B) {
+    b.ReportAllocs()
+    for range b.N {
+        next, stop := iter.Pull(iter.Seq[int](benchSource()))
+        for {
+            v, ok := next()
+            if !ok {
+                break
+            }
+            _ = v
+        }
+        stop()
+    }
+}
+
+func BenchmarkFilter(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Filter(benchSource(), func(v int) bool { return v%2 == 0 })
+    })
+}
+
+func BenchmarkFilter2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Filter2(benchSource2(), func(v1, _ int) bool { return v1%2 == 0 })
+    })
+}
+
+func BenchmarkOfType(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return OfType[int](Transform(benchSource(), func(v int) any { return v }))
+    })
+}
+
+func BenchmarkTake(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Take(benchSource(), benchSize/2)
+    })
+}
+
+func BenchmarkTake2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Take2(benchSource2(), benchSize/2)
+    })
+}
+
+func BenchmarkTakeLast(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return TakeLast(benchSource(), benchSize/2)
+    })
+}
+
+func BenchmarkTakeLast2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return TakeLast2(benchSource2(), benchSize/2)
+    })
+}
+
+func BenchmarkSkip(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Skip(benchSource(), benchSize/2)
+    })
+}
+
+func BenchmarkSkip2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Skip2(benchSource2(), benchSize/2)
+    })
+}
+
+func BenchmarkSkipLast(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return SkipLast(benchSource(), benchSize/2)
+    })
+}
+
+func BenchmarkSkipLast2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return SkipLast2(benchSource2(), benchSize/2)
+    })
+}
+
+func BenchmarkDistinct(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Distinct(benchSource())
+    })
+}
+
+func BenchmarkDistinctV1(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return DistinctV1(benchSource2())
+    })
+}
+
+func BenchmarkDistinctV2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return DistinctV2(benchSource2())
+    })
+}
+
+func BenchmarkDistinctBy(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return DistinctBy(benchSource(), func(v int) int { return v % 10 })
+    })
+}
+
+func BenchmarkDistinct2By(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Distinct2By(benchSource2(), func(v1, _ int) int { return v1 % 10 })
+    })
+}
+
+func BenchmarkSample(b *testing.B) {
+    rng := rand.New(rand.NewPCG(1, 2))
+    benchmark(b, func() Iterator[int] {
+        return Sample(benchSource(), 10, rng)
+    })
+}
+
+func BenchmarkSampleFraction(b *testing.B) {
+    rng := rand.New(rand.NewPCG(1, 2))
+    benchmark(b, func() Iterator[int] {
+        return SampleFraction(benchSource(), 0.1, rng)
+    })
+}
+
+func BenchmarkTransform(b *testing.B) {
+    benchmark(b, func() Iterator[string] {
+        return Transform(benchSource(), strconv.Itoa)
+    })
+}
+
+func BenchmarkTransform2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Transform2(benchSource2(), func(v1, v2 int) (int, int) { return v2, v1 })
+    })
+}
+
+func BenchmarkTransform12(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Transform12(benchSource(), func(v int) (int, int) { return v, v })
+    })
+}
+
+func BenchmarkTransform21(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Transform21(benchSource2(), func(v1, v2 int) int { return v1 + v2 })
+    })
+}
+
+func BenchmarkPickV1(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return PickV1(benchSource2())
+    })
+}
+
+func BenchmarkSwap(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Swap(benchSource2())
+    })
+}
+
+func BenchmarkScan(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Scan(benchSource(), 0, func(acc, v int) int { return acc + v })
+    })
+}
+
+func BenchmarkReduce(b *testing.B) {
+    b.ReportAllocs()
+    for range b.N {
+        Reduce(benchSource(), 0, func(acc, v int) int { return acc + v })
+    }
+}
+
+func BenchmarkCount(b *testing.B) {
+    b.ReportAllocs()
+    for range b.N {
+        Count(benchSource())
+    }
+}
+
+func BenchmarkZip(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Zip(benchSource(), benchSource())
+    })
+}
+
+func BenchmarkZipAs(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return ZipAs(benchSource(), benchSource(), func(z *Zipped[int, int]) int { return z.V1 + z.V2 }, true)
+    })
+}
+
+func BenchmarkCombine(b *testing.B) {
+    benchmark(b, func() Iterator[*Combined[int, int]] {
+        return Combine(benchSource2())
+    })
+}
+
+func BenchmarkConcat(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Concat(benchSource(), benchSource())
+    })
+}
+
+func BenchmarkConcat2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Concat2(benchSource2(), benchSource2())
+    })
+}
+
+func BenchmarkReverse(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Reverse(benchSource())
+    })
+}
+
+func BenchmarkReverse2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Reverse2(benchSource2())
+    })
+}
+
+func BenchmarkCache(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Cache(benchSource())
+    })
+}
+
+func BenchmarkCache2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Cache2(benchSource2())
+    })
+}
+
+// BenchmarkCacheHit iterates over the same cached iterator, so only the first iteration reads the source.
+func BenchmarkCacheHit(b *testing.B) {
+    cached := Cache(benchSource())
+    benchmark(b, func() Iterator[int] {
+        return cached
+    })
+}
+
+func BenchmarkOnce(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Once(benchSource())
+    })
+}
+
+func BenchmarkOnce2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Once2(benchSource2())
+    })
+}
+
+func BenchmarkFinishOnce(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return FinishOnce(benchSource())
+    })
+}
+
+func BenchmarkFinishOnce2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return FinishOnce2(benchSource2())
+    })
+}
+
+func BenchmarkOrder(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Order(benchSource(), true)
+    })
+}
+
+func BenchmarkOrder2V1(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Order2V1(benchSource2(), true)
+    })
+}
+
+func BenchmarkOrderBy(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return OrderBy(benchSource(), func(a, b int) int { return cmp.Compare(b, a) })
+    })
+}
+
+func BenchmarkOrder2By(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Order2By(benchSource2(), func(a, b *Combined[int, int]) int { return cmp.Compare(b.V1, a.V1) })
+    })
+}
+
+func BenchmarkOrder2ByTuple(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return Order2ByTuple(benchSource2(), func(a1, _, b1, _ int) int { return cmp.Compare(b1, a1) })
+    })
+}
+
+func BenchmarkStableOrder2By(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return StableOrder2By(benchSource2(), func(a, b *Combined[int, int]) int { return cmp.Compare(b.V1%10, a.V1%10) })
+    })
+}
+
+func BenchmarkStableOrder2ByTuple(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return StableOrder2ByTuple(benchSource2(), func(a1, _, b1, _ int) int { return cmp.Compare(b1%10, a1%10) })
+    })
+}
+
+func BenchmarkStableOrderBy(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return StableOrderBy(benchSource(), func(a, b int) int { return cmp.Compare(b%10, a%10) })
+    })
+}
+
+func BenchmarkShuffle(b *testing.B) {
+    rng := rand.New(rand.NewPCG(1, 2))
+    benchmark(b, func() Iterator[int] {
+        return Shuffle(benchSource(), rng)
+    })
+}
+
+func BenchmarkTopK(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return TopK(benchSource(), 10, cmp.Compare[int])
+    })
+}
+
+func BenchmarkTopK2(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, int] {
+        return TopK2(benchSource2(), 10, func(a, b *Combined[int, int]) int { return cmp.Compare(a.V1, b.V1) })
+    })
+}
+
+func BenchmarkCycle(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Take(Cycle(Range(1, 10)), benchSize)
+    })
+}
+
+func BenchmarkCursor(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return NewCursor(benchSource()).Rest()
+    })
+}
+
+func BenchmarkTap(b *testing.B) {
+    sum := 0
+    benchmark(b, func() Iterator[int] {
+        return Tap(benchSource(), func(v int) { sum += v })
+    })
+}
+
+func BenchmarkTraceDisabled(b *testing.B) {
+    // debug level is disabled by default
+    logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+    benchmark(b, func() Iterator[int] {
+        return Trace(benchSource(), logger, "bench")
+    })
+}
+
+func BenchmarkInstrument(b *testing.B) {
+    recorder := NewMemoryRecorder()
+    benchmark(b, func() Iterator[int] {
+        return Instrument(benchSource(), "bench", recorder)
+    })
+}
+
+func BenchmarkOnDone(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return OnDone(benchSource(), func(DoneReason) {})
+    })
+}
+
+func BenchmarkRecover(b *testing.B) {
+    benchmark2(b, func() Iterator2[int, error] {
+        return Recover(benchSource())
+    })
+}
+
+func BenchmarkStrict(b *testing.B) {
+    benchmark(b, func() Iterator[int] {
+        return Strict(benchSource())
+    })
+}
+
+func BenchmarkChain(b *testing.B) {
+    benchmark(b, func() Iterator[string] {
+        filtered := benchSource().Filter(func(v int) bool { return v%3 != 0 }).Skip(10).Take(benchSize / 2)
+        return Transform(filtered, strconv.Itoa)
+    })
+}
+
+func BenchmarkTimeRange(b *testing.B) {
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    benchmark(b, func() Iterator[time.Time] {
+        return TimeRange(start, start.Add(benchSize*time.Second), time.Second)
+    })
+}
@@ -0,0 +1,150 @@
+package goiter
+
+import (
+    "iter"
+    "sync"
+    "sync/atomic"
+)
+
+// Broadcaster pulls values from a source iterator in a background goroutine and delivers each of them to every live subscriber.
+// Subscribers only receive values produced after they joined, so a value yielded while nobody is subscribed is simply dropped.
+// For example:
+//
+//  b := goiter.NewBroadcaster(events)
+//  sub := b.Subscribe(16)
+//  go func() {
+//      for e := range sub {    // receives every event produced from now on, until the source ends or b.Close() is called
+//          handle(e)
+//      }
+//  }()
+//
+// Note: the broadcaster waits for a subscriber whose buffer is full, so a slow subscriber slows down all the others.
+// A subscription that is never iterated over will block the broadcaster once its buffer is full, so always range over it or break out of it.
+type Broadcaster[T any] struct {
+    mu          sync.Mutex
+    subscribers map[*subscriber[T]]struct{}
+    closed      bool
+    closeOnce   sync.Once
+    done        chan struct{}
+}
+
+type subscriber[T any] struct {
+    ch   chan T
+    quit chan struct{}
+    flag int32
+}
+
+// NewBroadcaster creates a Broadcaster and starts pulling values from the input iterator right away.
+// When the input iterator is exhausted, every subscriber will receive the values remaining in its buffer and then stop.
+func NewBroadcaster[TIter SeqX[T], T any](iterator TIter) *Broadcaster[T] {
+    b := &Broadcaster[T]{
+        subscribers: map[*subscriber[T]]struct{}{},
+        done:        make(chan struct{}),
+    }
+    go b.run(iter.Seq[T](iterator))
+    return b
+}
+
+// Subscribe joins the broadcaster and returns an iterator that yields the values produced from now on.
+// bufferSize is the number of values that can be queued for this subscriber before the broadcaster has to wait for it.
+// The returned iterator can only be iterated over once, breaking out of the loop unsubscribes it.
+// If the broadcaster has already been closed or its source is exhausted, the returned iterator yields nothing.
+func (b *Broadcaster[T]) Subscribe(bufferSize int) Iterator[T] {
+    if bufferSize < 0 {
+        bufferSize = 0
+    }
+
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    if b.closed {
+        return Empty[T]()
+    }
+    s := &subscriber[T]{
+        ch:   make(chan T, bufferSize),
+        quit: make(chan struct{}),
+    }
+    b.subscribers[s] = struct{}{}
+
+    return func(yield func(T) bool) {
+        if !atomic.CompareAndSwapInt32(&s.flag, 0, 1) {
+            return
+        }
+        defer b.unsubscribe(s)
+
+        for {
+            select {
+            case v, ok := <-s.ch:
+                if !ok {
+                    return
+                }
+                if !yield(v) {
+                    return
+                }
+            case <-b.done:
+                return
+            }
+        }
+    }
+}
+
+// Close stops the broadcaster, every subscriber stops right away, even if there are still values in its buffer.
+// It is safe to call Close multiple times and from multiple goroutines.
+func (b *Broadcaster[T]) Close() {
+    b.closeOnce.Do(func() {
+        b.mu.Lock()
+        b.closed = true
+        b.mu.Unlock()
+        close(b.done)
+    })
+}
+
+func (b *Broadcaster[T]) run(source iter.Seq[T]) {
+    defer b.finish()
+    for v := range source {
+        if !b.publish(v) {
+            return
+        }
+    }
+}
+
+func (b *Broadcaster[T]) publish(v T) bool {
+    b.mu.Lock()
+    if b.closed {
+        b.mu.Unlock()
+        return false
+    }
+    subs := make([]*subscriber[T], 0, len(b.subscribers))
+    for s := range b.subscribers {
+        subs = append(subs, s)
+    }
+    b.mu.Unlock()
+
+    for _, s := range subs {
+        select {
+        case s.ch <- v:
+        case <-s.quit:
+        case <-b.done:
+            return false
+        }
+    }
+    return true
+}
+
+// finish is called once the source stops, it is the only place the subscriber channels get closed,
+// because the goroutine running the source is the only sender.
+func (b *Broadcaster[T]) finish() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.closed = true
+    for s := range b.subscribers {
+        close(s.ch)
+    }
+    clear(b.subscribers)
+}
+
+func (b *Broadcaster[T]) unsubscribe(s *subscriber[T]) {
+    b.mu.Lock()
+    delete(b.subscribers, s)
+    b.mu.Unlock()
+    close(s.quit)
+}
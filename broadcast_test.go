@@ -0,0 +1,142 @@
+package goiter
+
+import (
+    "fmt"
+    "slices"
+    "sync"
+    "testing"
+)
+
+func TestBroadcaster(t *testing.T) {
+    // case 1: every subscriber receives all values produced after it joined
+    start := make(chan struct{})
+    source := func(yield func(int) bool) {
+        <-start
+        for i := 1; i <= 5; i++ {
+            if !yield(i) {
+                return
+            }
+        }
+    }
+    b := NewBroadcaster(source)
+    sub1 := b.Subscribe(0)
+    sub2 := b.Subscribe(10)
+    close(start)
+
+    actual1 := []int{}
+    actual2 := []int{}
+    g := &sync.WaitGroup{}
+    g.Add(2)
+    go func() {
+        defer g.Done()
+        for v := range sub1 {
+            actual1 = append(actual1, v)
+        }
+    }()
+    go func() {
+        defer g.Done()
+        for v := range sub2 {
+            actual2 = append(actual2, v)
+        }
+    }()
+    g.Wait()
+    expect := []int{1, 2, 3, 4, 5}
+    if !slices.Equal(expect, actual1) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual1))
+    }
+    if !slices.Equal(expect, actual2) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual2))
+    }
+
+    // subscribing after the source is exhausted yields nothing
+    if c := Count(b.Subscribe(1)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+}
+
+func TestBroadcaster_Unsubscribe(t *testing.T) {
+    ch := make(chan int)
+    source := func(yield func(int) bool) {
+        for v := range ch {
+            if !yield(v) {
+                return
+            }
+        }
+    }
+    b := NewBroadcaster(source)
+    defer b.Close()
+    sub1 := b.Subscribe(0)
+    sub2 := b.Subscribe(0)
+
+    actual2 := []int{}
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        for v := range sub2 {
+            actual2 = append(actual2, v)
+            if v == 2 {
+                break
+            }
+        }
+    }()
+
+    actual1 := []int{}
+    done1 := make(chan struct{})
+    go func() {
+        defer close(done1)
+        for v := range sub1 {
+            actual1 = append(actual1, v)
+        }
+    }()
+
+    ch <- 1
+    ch <- 2
+    <-done
+    // sub2 has left, so it won't block the broadcaster anymore
+    ch <- 3
+    ch <- 4
+    close(ch)
+    <-done1
+
+    expect1 := []int{1, 2, 3, 4}
+    if !slices.Equal(expect1, actual1) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect1, actual1))
+    }
+    expect2 := []int{1, 2}
+    if !slices.Equal(expect2, actual2) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect2, actual2))
+    }
+
+    // a subscription can only be iterated over once
+    if c := Count(sub2); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+}
+
+func TestBroadcaster_Close(t *testing.T) {
+    source := func(yield func(int) bool) {
+        for i := 0; ; i++ {
+            if !yield(i) {
+                return
+            }
+        }
+    }
+    b := NewBroadcaster(source)
+    sub := b.Subscribe(0)
+
+    count := 0
+    for range sub {
+        count++
+        if count == 3 {
+            b.Close()
+        }
+    }
+    if count < 3 {
+        t.Fatal(fmt.Sprintf("expect at least 3 values, actual: %d", count))
+    }
+
+    b.Close()
+    if c := Count(b.Subscribe(1)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+}
@@ -0,0 +1,275 @@
+package goiter
+
+// Permutations returns an iterator that yields all the k-length permutations of the elements of the slice,
+// in lexicographic order of the element positions, so if the slice is sorted, the permutations are yielded in sorted order.
+// Elements are treated as unique based on their positions, not their values.
+// Each permutation is generated on demand, so nothing is precomputed.
+// For example:
+//
+//	goiter.Permutations([]int{1, 2, 3}, 2) // will yield [1 2] [1 3] [2 1] [2 3] [3 1] [3 2]
+//
+// If k is 0, it yields one empty slice, if k is negative or greater than len(s), it yields nothing.
+// By default, each yielded slice is newly allocated. If the optional reuse parameter is true,
+// the same slice is reused and overwritten for every permutation to avoid allocations, so you must copy it if you want to keep it.
+func Permutations[S ~[]T, T any](s S, k int, reuse ...bool) Iterator[[]T] {
+    return func(yield func([]T) bool) {
+        n := len(s)
+        if k < 0 || k > n {
+            return
+        }
+        out := newCombinatoricsOutput(s, k, reuse...)
+
+        indices := make([]int, n)
+        for i := range indices {
+            indices[i] = i
+        }
+        cycles := make([]int, k)
+        for i := range cycles {
+            cycles[i] = n - i
+        }
+        if !yield(out.fill(indices[:k])) {
+            return
+        }
+
+        for n > 0 {
+            advanced := false
+            for i := k - 1; i >= 0; i-- {
+                cycles[i]--
+                if cycles[i] == 0 {
+                    // rotate indices[i:] left by one
+                    first := indices[i]
+                    copy(indices[i:], indices[i+1:])
+                    indices[n-1] = first
+                    cycles[i] = n - i
+                    continue
+                }
+                j := n - cycles[i]
+                indices[i], indices[j] = indices[j], indices[i]
+                advanced = true
+                break
+            }
+            if !advanced {
+                return
+            }
+            if !yield(out.fill(indices[:k])) {
+                return
+            }
+        }
+    }
+}
+
+// Combinations returns an iterator that yields all the k-length combinations of the elements of the slice,
+// in lexicographic order of the element positions, elements are treated as unique based on their positions, not their values.
+// For example:
+//
+//	goiter.Combinations([]string{"a", "b", "c", "d"}, 2) // will yield [a b] [a c] [a d] [b c] [b d] [c d]
+//
+// If k is 0, it yields one empty slice, if k is negative or greater than len(s), it yields nothing.
+// The optional reuse parameter works the same as in Permutations.
+func Combinations[S ~[]T, T any](s S, k int, reuse ...bool) Iterator[[]T] {
+    return func(yield func([]T) bool) {
+        n := len(s)
+        if k < 0 || k > n {
+            return
+        }
+        out := newCombinatoricsOutput(s, k, reuse...)
+
+        indices := make([]int, k)
+        for i := range indices {
+            indices[i] = i
+        }
+        if !yield(out.fill(indices)) {
+            return
+        }
+
+        for {
+            i := k - 1
+            for i >= 0 && indices[i] == i+n-k {
+                i--
+            }
+            if i < 0 {
+                return
+            }
+            indices[i]++
+            for j := i + 1; j < k; j++ {
+                indices[j] = indices[j-1] + 1
+            }
+            if !yield(out.fill(indices)) {
+                return
+            }
+        }
+    }
+}
+
+// CombinationsWithReplacement is like Combinations, but each element can be chosen more than once.
+// For example:
+//
+//	goiter.CombinationsWithReplacement([]string{"a", "b", "c"}, 2) // will yield [a a] [a b] [a c] [b b] [b c] [c c]
+//
+// If k is 0, it yields one empty slice, if k is negative, or the slice is empty while k is positive, it yields nothing.
+// The optional reuse parameter works the same as in Permutations.
+func CombinationsWithReplacement[S ~[]T, T any](s S, k int, reuse ...bool) Iterator[[]T] {
+    return func(yield func([]T) bool) {
+        n := len(s)
+        if k < 0 || (n == 0 && k > 0) {
+            return
+        }
+        out := newCombinatoricsOutput(s, k, reuse...)
+
+        indices := make([]int, k)
+        if !yield(out.fill(indices)) {
+            return
+        }
+
+        for {
+            i := k - 1
+            for i >= 0 && indices[i] == n-1 {
+                i--
+            }
+            if i < 0 {
+                return
+            }
+            v := indices[i] + 1
+            for j := i; j < k; j++ {
+                indices[j] = v
+            }
+            if !yield(out.fill(indices)) {
+                return
+            }
+        }
+    }
+}
+
+// PowerSet returns an iterator that yields all the subsets of the elements of the slice, in lexicographic order of the element positions.
+// For example:
+//
+//	goiter.PowerSet([]int{1, 2, 3}) // will yield [] [1] [1 2] [1 2 3] [1 3] [2] [2 3] [3]
+//
+// The optional reuse parameter works the same as in Permutations.
+func PowerSet[S ~[]T, T any](s S, reuse ...bool) Iterator[[]T] {
+    return func(yield func([]T) bool) {
+        n := len(s)
+        out := newCombinatoricsOutput(s, n, reuse...)
+
+        indices := make([]int, 0, n)
+        if !yield(out.fill(indices)) {
+            return
+        }
+
+        for {
+            // extend the subset with the next position if there is one, otherwise drop the last position and advance the new last one.
+            last := len(indices) - 1
+            switch {
+            case last < 0 && n == 0:
+                return
+            case last < 0:
+                indices = append(indices, 0)
+            case indices[last] < n-1:
+                indices = append(indices, indices[last]+1)
+            case last == 0:
+                return
+            default:
+                indices = indices[:last]
+                indices[last-1]++
+            }
+            if !yield(out.fill(indices)) {
+                return
+            }
+        }
+    }
+}
+
+// CartesianProduct returns an iterator that yields the cartesian product of the values of the input iterators,
+// the rightmost iterator advances the fastest, like nested for loops, so if every input is sorted, the products are in lexicographic order.
+// For example:
+//
+//	goiter.CartesianProduct(goiter.Items(1, 2), goiter.Items(3, 4)) // will yield [1 3] [1 4] [2 3] [2 4]
+//
+// Values of the input iterators are buffered when the iteration starts, because each of them has to be traversed more than once,
+// so the input iterators must be finite. The products themselves are generated on demand.
+// If there is no input iterator, it yields one empty slice, and if any input iterator yields nothing, it yields nothing.
+// Each yielded slice is newly allocated, use CartesianProductReuse to avoid the allocations.
+func CartesianProduct[TIter SeqX[T], T any](iterators ...TIter) Iterator[[]T] {
+    return cartesianProduct(iterators, false)
+}
+
+// CartesianProductReuse is like CartesianProduct, but the same slice is reused and overwritten for every product to avoid allocations,
+// so you must copy it if you want to keep it. It works the same as passing true as the optional reuse parameter of Permutations,
+// which CartesianProduct cannot take since its parameters are already variadic.
+func CartesianProductReuse[TIter SeqX[T], T any](iterators ...TIter) Iterator[[]T] {
+    return cartesianProduct(iterators, true)
+}
+
+func cartesianProduct[TIter SeqX[T], T any](iterators []TIter, reuse bool) Iterator[[]T] {
+    return func(yield func([]T) bool) {
+        pools := make([][]T, len(iterators))
+        for i, it := range iterators {
+            for v := range it {
+                pools[i] = append(pools[i], v)
+            }
+            if len(pools[i]) == 0 {
+                return
+            }
+        }
+
+        indices := make([]int, len(pools))
+        var buffer []T
+        if reuse {
+            buffer = make([]T, len(pools))
+        }
+        for {
+            product := buffer
+            if !reuse {
+                product = make([]T, len(pools))
+            }
+            for i, idx := range indices {
+                product[i] = pools[i][idx]
+            }
+            if !yield(product) {
+                return
+            }
+
+            i := len(indices) - 1
+            for i >= 0 {
+                indices[i]++
+                if indices[i] < len(pools[i]) {
+                    break
+                }
+                indices[i] = 0
+                i--
+            }
+            if i < 0 {
+                return
+            }
+        }
+    }
+}
+
+type combinatoricsOutput[T any] struct {
+    source []T
+    buffer []T
+    reuse  bool
+}
+
+func newCombinatoricsOutput[S ~[]T, T any](s S, k int, reuse ...bool) *combinatoricsOutput[T] {
+    out := &combinatoricsOutput[T]{
+        source: s,
+        reuse:  len(reuse) > 0 && reuse[0],
+    }
+    if out.reuse {
+        out.buffer = make([]T, k)
+    }
+    return out
+}
+
+func (o *combinatoricsOutput[T]) fill(indices []int) []T {
+    buffer := o.buffer
+    if !o.reuse {
+        buffer = make([]T, len(indices))
+    }
+    buffer = buffer[:len(indices)]
+    for i, idx := range indices {
+        buffer[i] = o.source[idx]
+    }
+    return buffer
+}
@@ -0,0 +1,161 @@
+package goiter
+
+import (
+    "fmt"
+    "slices"
+    "testing"
+)
+
+func TestPermutations(t *testing.T) {
+    actual := collectSlices(Permutations([]int{1, 2, 3}, 2))
+    expect := [][]int{{1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}}
+    if !slices.EqualFunc(expect, actual, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = collectSlices(Permutations([]int{1, 2, 3}, 3, true))
+    expect = [][]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}}
+    if !slices.EqualFunc(expect, actual, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(Permutations([]int{1, 2, 3, 4, 5}, 3)); c != 60 {
+        t.Fatal(fmt.Sprintf("expect: 60, actual: %d", c))
+    }
+    if c := Count(Permutations([]int{1, 2}, 0)); c != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1, actual: %d", c))
+    }
+    if c := Count(Permutations([]int{1, 2}, 3)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(Permutations([]int{1, 2}, -1)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+
+    // the buffer is reused
+    var prev []int
+    for p := range Permutations([]int{1, 2, 3}, 2, true) {
+        if prev != nil && &prev[0] != &p[0] {
+            t.Fatal("expect the yielded slice to be reused")
+        }
+        prev = p
+    }
+
+    for _ = range Permutations([]int{1, 2, 3}, 2) {
+        break
+    }
+}
+
+func TestCombinations(t *testing.T) {
+    actual := collectSlices(Combinations([]string{"a", "b", "c", "d"}, 2))
+    expect := [][]string{{"a", "b"}, {"a", "c"}, {"a", "d"}, {"b", "c"}, {"b", "d"}, {"c", "d"}}
+    if !slices.EqualFunc(expect, actual, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(Combinations(make([]int, 10), 4, true)); c != 210 {
+        t.Fatal(fmt.Sprintf("expect: 210, actual: %d", c))
+    }
+    if c := Count(Combinations([]int{1, 2}, 0)); c != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1, actual: %d", c))
+    }
+    if c := Count(Combinations([]int{1, 2}, 3)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+
+    for _ = range Combinations([]int{1, 2, 3}, 2) {
+        break
+    }
+}
+
+func TestCombinationsWithReplacement(t *testing.T) {
+    actual := collectSlices(CombinationsWithReplacement([]string{"a", "b", "c"}, 2))
+    expect := [][]string{{"a", "a"}, {"a", "b"}, {"a", "c"}, {"b", "b"}, {"b", "c"}, {"c", "c"}}
+    if !slices.EqualFunc(expect, actual, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(CombinationsWithReplacement([]int{1, 2, 3, 4}, 3, true)); c != 20 {
+        t.Fatal(fmt.Sprintf("expect: 20, actual: %d", c))
+    }
+    if c := Count(CombinationsWithReplacement([]int{}, 2)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(CombinationsWithReplacement([]int{}, 0)); c != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1, actual: %d", c))
+    }
+
+    for _ = range CombinationsWithReplacement([]int{1, 2, 3}, 2) {
+        break
+    }
+}
+
+func TestPowerSet(t *testing.T) {
+    actual := collectSlices(PowerSet([]int{1, 2, 3}))
+    expect := [][]int{{}, {1}, {1, 2}, {1, 2, 3}, {1, 3}, {2}, {2, 3}, {3}}
+    if !slices.EqualFunc(expect, actual, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    // the buffer is reused, and it is resliced to the size of each subset
+    reused := [][]int{}
+    for subset := range PowerSet([]int{1, 2, 3}, true) {
+        reused = append(reused, slices.Clone(subset))
+    }
+    if !slices.EqualFunc(expect, reused, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, reused))
+    }
+
+    if c := Count(PowerSet([]int{})); c != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1, actual: %d", c))
+    }
+
+    if c := Count(PowerSet(make([]int, 10), true)); c != 1024 {
+        t.Fatal(fmt.Sprintf("expect: 1024, actual: %d", c))
+    }
+
+    for _ = range PowerSet([]int{1, 2, 3}) {
+        break
+    }
+}
+
+func TestCartesianProduct(t *testing.T) {
+    actual := collectSlices(CartesianProduct(Items(1, 2), Items(3, 4), Items(5)))
+    expect := [][]int{{1, 3, 5}, {1, 4, 5}, {2, 3, 5}, {2, 4, 5}}
+    if !slices.EqualFunc(expect, actual, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(CartesianProduct(Items(1, 2), Empty[int]())); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(CartesianProduct[Iterator[int]]()); c != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1, actual: %d", c))
+    }
+
+    // the buffer is reused
+    actual = collectSlices(CartesianProductReuse(Items(1, 2), Items(3, 4)))
+    expect = [][]int{{1, 3}, {1, 4}, {2, 3}, {2, 4}}
+    if !slices.EqualFunc(expect, actual, slices.Equal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    var prev []int
+    for p := range CartesianProductReuse(Items(1, 2), Items(3, 4)) {
+        if prev != nil && &prev[0] != &p[0] {
+            t.Fatal("expect the yielded slice to be reused")
+        }
+        prev = p
+    }
+
+    for _ = range CartesianProduct(Items(1, 2), Items(3, 4)) {
+        break
+    }
+}
+
+func collectSlices[T any](iterator Iterator[[]T]) [][]T {
+    result := [][]T{}
+    for each := range iterator {
+        result = append(result, slices.Clone(each))
+    }
+    return result
+}
@@ -39,15 +39,13 @@ func Zip[TIter1 SeqX[T1], TIter2 SeqX[T2], T1, T2 any](
     iterator2 TIter2,
 ) Iterator2[T1, T2] {
     return func(yield func(T1, T2) bool) {
-        p1, stop1 := iter.Pull(iter.Seq[T1](iterator1))
-        defer stop1()
-        p2, stop2 := iter.Pull(iter.Seq[T2](iterator2))
+        // only the second iterator has to be pulled, the first one drives the loop
+        p2, stop2 := pull(iter.Seq[T2](iterator2))
         defer stop2()
 
-        for {
-            v1, ok1 := p1()
+        for v1 := range iterator1 {
             v2, ok2 := p2()
-            if !ok1 || !ok2 {
+            if !ok2 {
                 return
             }
 
@@ -67,24 +65,19 @@ func ZipAs[TIter1 SeqX[T1], TIter2 SeqX[T2], TOut, T1, T2 any](
     exhaust bool,
 ) Iterator[TOut] {
     return func(yield func(TOut) bool) {
-        p1, stop1 := iter.Pull(iter.Seq[T1](iterator1))
-        defer stop1()
-        p2, stop2 := iter.Pull(iter.Seq[T2](iterator2))
+        // only the second iterator has to be pulled, the first one drives the loop
+        p2, stop2 := pull(iter.Seq[T2](iterator2))
         defer stop2()
 
-        for {
-            in1, ok1 := p1()
+        for in1 := range iterator1 {
             in2, ok2 := p2()
-            if !ok1 && !ok2 {
-                return
-            }
-            if (!ok1 || !ok2) && !exhaust {
+            if !ok2 && !exhaust {
                 return
             }
 
             out := transformer(&Zipped[T1, T2]{
                 V1:  in1,
-                OK1: ok1,
+                OK1: true,
                 V2:  in2,
                 OK2: ok2,
             })
@@ -92,6 +85,28 @@ func ZipAs[TIter1 SeqX[T1], TIter2 SeqX[T2], TOut, T1, T2 any](
                 return
             }
         }
+        if !exhaust {
+            return
+        }
+
+        // the first iterator has stopped, yield the rest of the second one
+        var zero T1
+        for {
+            in2, ok2 := p2()
+            if !ok2 {
+                return
+            }
+
+            out := transformer(&Zipped[T1, T2]{
+                V1:  zero,
+                OK1: false,
+                V2:  in2,
+                OK2: true,
+            })
+            if !yield(out) {
+                return
+            }
+        }
     }
 }
 
@@ -119,6 +119,23 @@ func TestZipAs(t *testing.T) {
         t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
     }
 
+    // case 3, the first iterator is longer
+    nameIter = SliceElems([]string{"Alice", "Bob", "Eve"})
+    ageIter = SliceElems([]int{20})
+    zipIter = ZipAs(nameIter, ageIter, transformer, true)
+    actual = make([]person, 0, 3)
+    for each := range zipIter {
+        actual = append(actual, each)
+    }
+    expect = []person{
+        {Name: "Alice", Age: 20},
+        {Name: "Bob", Age: -1},
+        {Name: "Eve", Age: -1},
+    }
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
     for _ = range zipIter {
         break
     }
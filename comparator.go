@@ -0,0 +1,159 @@
+package goiter
+
+import (
+    "cmp"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// Comparator is a comparison function that can be passed to OrderBy, StableOrderBy, TopK and so on.
+// It can be built by By, ByDesc or ByCompare, and then be chained to compare by multiple keys.
+// For example:
+//
+//	// sort people by age, then by name in descending order, then by nickname case-insensitively
+//	comparator := goiter.By(func(p Person) int { return p.Age }).
+//		ThenByDesc(goiter.By(func(p Person) string { return p.Name })).
+//		ThenBy(goiter.ByCompare(func(p Person) string { return p.Nickname }, goiter.CaseInsensitive))
+//	for p := range goiter.SliceElems(people).OrderBy(comparator) {
+//		fmt.Println(p)
+//	}
+type Comparator[T any] func(T, T) int
+
+// By returns a Comparator that compares the keys selected by the key function in ascending order.
+func By[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+    return func(a, b T) int {
+        return cmp.Compare(key(a), key(b))
+    }
+}
+
+// ByDesc is like By, but it compares the keys in descending order.
+func ByDesc[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+    return By(key).Reverse()
+}
+
+// ByCompare returns a Comparator that compares the keys selected by the key function using the given comparison function.
+// It is useful when the keys are not ordered types, or when they need a special ordering like CaseInsensitive or NaturalOrder.
+func ByCompare[T any, K any](key func(T) K, cmp func(K, K) int) Comparator[T] {
+    return func(a, b T) int {
+        return cmp(key(a), key(b))
+    }
+}
+
+// ThenBy returns a Comparator that uses the next comparator to break the ties of the current one.
+func (c Comparator[T]) ThenBy(next Comparator[T]) Comparator[T] {
+    return func(a, b T) int {
+        if r := c(a, b); r != 0 {
+            return r
+        }
+        return next(a, b)
+    }
+}
+
+// ThenByDesc is like ThenBy, but the ties are broken by the next comparator in reverse order.
+func (c Comparator[T]) ThenByDesc(next Comparator[T]) Comparator[T] {
+    return c.ThenBy(next.Reverse())
+}
+
+// Reverse returns a Comparator that orders elements in the opposite order.
+func (c Comparator[T]) Reverse() Comparator[T] {
+    return func(a, b T) int {
+        return c(b, a)
+    }
+}
+
+// NilFirst turns a comparison function of values into a comparison function of pointers, where nil pointers come first.
+// For example:
+//
+//	goiter.ByCompare(func(t *Task) *time.Time { return t.Deadline }, goiter.NilFirst(time.Time.Compare))
+func NilFirst[K any](cmp func(K, K) int) func(*K, *K) int {
+    return func(a, b *K) int {
+        switch {
+        case a == nil && b == nil:
+            return 0
+        case a == nil:
+            return -1
+        case b == nil:
+            return 1
+        }
+        return cmp(*a, *b)
+    }
+}
+
+// NilLast is like NilFirst, but nil pointers come last.
+func NilLast[K any](cmp func(K, K) int) func(*K, *K) int {
+    return func(a, b *K) int {
+        switch {
+        case a == nil && b == nil:
+            return 0
+        case a == nil:
+            return 1
+        case b == nil:
+            return -1
+        }
+        return cmp(*a, *b)
+    }
+}
+
+// CaseInsensitive compares two strings ignoring the case of letters.
+// Strings that differ only in case are then ordered by strings.Compare, so the result is deterministic.
+func CaseInsensitive(a, b string) int {
+    if r := compareFold(a, b); r != 0 {
+        return r
+    }
+    return strings.Compare(a, b)
+}
+
+// NaturalOrder compares two strings treating the digit sequences in them as numbers,
+// so "file9" comes before "file10", which is different from strings.Compare.
+// Numbers of equal value but with different leading zeros, like "a01" and "a1", are then ordered by strings.Compare.
+func NaturalOrder(a, b string) int {
+    i, j := 0, 0
+    for i < len(a) && j < len(b) {
+        ca, cb := a[i], b[j]
+        if isDigit(ca) && isDigit(cb) {
+            si := i
+            for i < len(a) && isDigit(a[i]) {
+                i++
+            }
+            sj := j
+            for j < len(b) && isDigit(b[j]) {
+                j++
+            }
+            na := strings.TrimLeft(a[si:i], "0")
+            nb := strings.TrimLeft(b[sj:j], "0")
+            if r := cmp.Compare(len(na), len(nb)); r != 0 {
+                return r
+            }
+            if r := strings.Compare(na, nb); r != 0 {
+                return r
+            }
+            continue
+        }
+        if ca != cb {
+            return cmp.Compare(ca, cb)
+        }
+        i++
+        j++
+    }
+    if r := cmp.Compare(len(a)-i, len(b)-j); r != 0 {
+        return r
+    }
+    return strings.Compare(a, b)
+}
+
+func compareFold(a, b string) int {
+    for a != "" && b != "" {
+        ra, sizeA := utf8.DecodeRuneInString(a)
+        rb, sizeB := utf8.DecodeRuneInString(b)
+        if r := cmp.Compare(unicode.ToLower(ra), unicode.ToLower(rb)); r != 0 {
+            return r
+        }
+        a, b = a[sizeA:], b[sizeB:]
+    }
+    return cmp.Compare(len(a), len(b))
+}
+
+func isDigit(c byte) bool {
+    return '0' <= c && c <= '9'
+}
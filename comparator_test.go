@@ -0,0 +1,112 @@
+package goiter
+
+import (
+    "cmp"
+    "fmt"
+    "slices"
+    "testing"
+)
+
+func TestComparator(t *testing.T) {
+    type person struct {
+        name string
+        age  int
+        nick string
+    }
+    input := []person{
+        {"bob", 25, "b"},
+        {"eve", 30, "E"},
+        {"alice", 25, "a"},
+        {"bob", 20, "B2"},
+        {"bob", 25, "a"},
+    }
+
+    comparator := By(func(p person) string { return p.name }).
+        ThenByDesc(By(func(p person) int { return p.age })).
+        ThenBy(ByCompare(func(p person) string { return p.nick }, CaseInsensitive))
+    actual := []person{}
+    for v := range SliceElems(input).OrderBy(comparator) {
+        actual = append(actual, v)
+    }
+    expect := []person{
+        {"alice", 25, "a"},
+        {"bob", 25, "a"},
+        {"bob", 25, "b"},
+        {"bob", 20, "B2"},
+        {"eve", 30, "E"},
+    }
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = []person{}
+    for v := range StableOrderBy(SliceElems(input), ByDesc(func(p person) int { return p.age })) {
+        actual = append(actual, v)
+    }
+    expect = []person{
+        {"eve", 30, "E"},
+        {"bob", 25, "b"},
+        {"alice", 25, "a"},
+        {"bob", 25, "a"},
+        {"bob", 20, "B2"},
+    }
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+}
+
+func TestNilFirstNilLast(t *testing.T) {
+    one, two := 1, 2
+    input := []*int{&two, nil, &one, nil}
+
+    actual := []string{}
+    for v := range SliceElems(input).OrderBy(NilFirst(cmp.Compare[int])) {
+        actual = append(actual, ptrString(v))
+    }
+    expect := []string{"nil", "nil", "1", "2"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = []string{}
+    for v := range SliceElems(input).OrderBy(NilLast(cmp.Compare[int])) {
+        actual = append(actual, ptrString(v))
+    }
+    expect = []string{"1", "2", "nil", "nil"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+}
+
+func TestCaseInsensitive(t *testing.T) {
+    actual := []string{}
+    for v := range Items("banana", "Apple", "apple", "Äpfel", "cherry", "BANANA", "ap").OrderBy(CaseInsensitive) {
+        actual = append(actual, v)
+    }
+    expect := []string{"ap", "Apple", "apple", "BANANA", "banana", "cherry", "Äpfel"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+}
+
+func TestNaturalOrder(t *testing.T) {
+    actual := []string{}
+    for v := range Items("file10", "file9", "file1", "file01", "file", "file10a", "file10b2", "file10b10", "a100", "a20").OrderBy(NaturalOrder) {
+        actual = append(actual, v)
+    }
+    expect := []string{"a20", "a100", "file", "file01", "file1", "file9", "file10", "file10a", "file10b2", "file10b10"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if NaturalOrder("x2", "x2") != 0 {
+        t.Fatal("expect equal strings to be equal")
+    }
+}
+
+func ptrString(v *int) string {
+    if v == nil {
+        return "nil"
+    }
+    return fmt.Sprintf("%d", *v)
+}
@@ -0,0 +1,83 @@
+package goiter_test
+
+import (
+    "cmp"
+    "testing"
+
+    "github.com/hsldymq/goiter"
+    "github.com/hsldymq/goiter/goitertest"
+)
+
+// TestOperatorContracts checks the operators with goitertest: they stop calling yield once the consumer breaks out of the loop,
+// they do not leak the goroutines of iter.Pull, and they can be iterated over repeatedly.
+func TestOperatorContracts(t *testing.T) {
+    goiter.SetDebug(true)
+    defer goiter.SetDebug(false)
+    t.Cleanup(func() { goitertest.AssertNoLivePulls(t) })
+
+    source := goiter.Range(1, 6)
+    source2 := goiter.Slice([]int{5, 3, 1, 4, 2})
+    operators := map[string]goiter.Iterator[int]{
+        "Filter":      goiter.Filter(source, func(v int) bool { return v%2 == 0 }),
+        "OfType":      goiter.OfType[int](source),
+        "Take":        goiter.Take(source, 4),
+        "TakeLast":    goiter.TakeLast(source, 4),
+        "Skip":        goiter.Skip(source, 2),
+        "SkipLast":    goiter.SkipLast(source, 2),
+        "Distinct":    goiter.Distinct(goiter.Items(1, 1, 2, 3, 2)),
+        "DistinctBy":  goiter.DistinctBy(source, func(v int) int { return v % 3 }),
+        "Transform":   goiter.Transform(source, func(v int) int { return v * 2 }),
+        "Scan":        goiter.Scan(source, 0, func(acc, v int) int { return acc + v }),
+        "ZipAs":       goiter.ZipAs(source, goiter.Range(0, 100), func(z *goiter.Zipped[int, int]) int { return z.V1 + z.V2 }, false),
+        "Concat":      goiter.Concat(source, source),
+        "Reverse":     goiter.Reverse(source),
+        "Cache":       goiter.Cache(source),
+        "Order":       goiter.Order(source2.PickV2()),
+        "TopK":        goiter.TopK(source, 3, cmp.Compare[int]),
+        "Repeat":      goiter.Repeat(1, 3),
+        "PickV2":      goiter.PickV2(source2),
+        "RangeStep":   goiter.RangeStep(1, 10, 3),
+        "Exclusive":   goiter.RangeExclusive(0, 5).Iter(),
+        "Take(Cycle)": goiter.Take(goiter.Cycle(source), 8),
+    }
+    for name, iterator := range operators {
+        t.Run(name, func(t *testing.T) {
+            goitertest.AssertEarlyBreakSafe(t, iterator)
+            goitertest.AssertReusable(t, iterator)
+        })
+    }
+
+    operators2 := map[string]goiter.Iterator2[int, int]{
+        "Filter2":    goiter.Filter2(source2, func(_, v int) bool { return v > 2 }),
+        "Take2":      goiter.Take2(source2, 3),
+        "TakeLast2":  goiter.TakeLast2(source2, 3),
+        "Skip2":      goiter.Skip2(source2, 2),
+        "SkipLast2":  goiter.SkipLast2(source2, 2),
+        "DistinctV2": goiter.DistinctV2(goiter.Slice([]int{1, 1, 2})),
+        "Transform2": goiter.Transform2(source2, func(i, v int) (int, int) { return v, i }),
+        "Zip":        goiter.Zip(source, goiter.Range(0, 100)),
+        "Reverse2":   goiter.Reverse2(source2),
+        "Cache2":     goiter.Cache2(source2),
+        "Order2V2":   goiter.Order2V2(source2),
+        "Swap":       goiter.Swap(source2),
+        "Concat2":    goiter.Concat2(source2, source2),
+    }
+    for name, iterator := range operators2 {
+        t.Run(name, func(t *testing.T) {
+            goitertest.AssertEarlyBreakSafe2(t, iterator)
+            goitertest.AssertReusable2(t, iterator)
+        })
+    }
+}
+
+func TestUnrepeatableContracts(t *testing.T) {
+    goitertest.AssertSingleUse(t, goiter.Once(goiter.Range(1, 3)))
+    goitertest.AssertSingleUse2(t, goiter.Once2(goiter.Slice([]int{1, 2})))
+    goitertest.AssertResumable(t, goiter.FinishOnce(goiter.Range(1, 3)), 1, 2, 3)
+}
+
+func TestCombinatoricsGolden(t *testing.T) {
+    goitertest.AssertGolden(t, goiter.Permutations([]string{"a", "b", "c"}, 2), "permutations")
+    goitertest.AssertGolden(t, goiter.Combinations([]int{1, 2, 3, 4}, 2), "combinations")
+    goitertest.AssertGolden(t, goiter.PowerSet([]int{1, 2, 3}), "power_set")
+}
@@ -0,0 +1,175 @@
+package goiter
+
+import (
+    "iter"
+    "slices"
+)
+
+// Cursor provides a pull-style way to walk through an iterator with lookahead, which is handy for writing hand-made parsers.
+// Compared to iter.Pull, it also allows you to peek values without consuming them and to push values back.
+// For example:
+//
+//  c := goiter.NewCursor(goiter.Items("a", "=", "1"))
+//  defer c.Stop()
+//  name, _ := c.Next()                     // name is "a"
+//  if op, _ := c.Peek(); op == "=" {       // Peek does not consume "="
+//      c.Next()
+//  }
+//  for v := range c.Rest() {               // Rest continues from the current position, it yields "1"
+//      fmt.Println(v)
+//  }
+//
+// A Cursor is not safe for concurrent use, and you should call Stop when you are done with it, unless it has been exhausted.
+type Cursor[T any] struct {
+    next    func() (T, bool)
+    stop    func()
+    pending []T
+    stopped bool
+}
+
+// NewCursor creates a Cursor positioned before the first value of the input iterator.
+func NewCursor[TIter SeqX[T], T any](iterator TIter) *Cursor[T] {
+    next, stop := pull(iter.Seq[T](iterator))
+    return &Cursor[T]{
+        next: next,
+        stop: stop,
+    }
+}
+
+// Next consumes and returns the next value, the second return value is false if there are no more values.
+func (c *Cursor[T]) Next() (T, bool) {
+    if len(c.pending) > 0 {
+        v := c.pending[0]
+        c.pending = c.pending[1:]
+        return v, true
+    }
+    return c.pull()
+}
+
+// Peek returns the next value without consuming it, the second return value is false if there are no more values.
+func (c *Cursor[T]) Peek() (T, bool) {
+    if !c.fill(1) {
+        var zero T
+        return zero, false
+    }
+    return c.pending[0], true
+}
+
+// PeekN returns up to n next values without consuming them, fewer values are returned if the iterator ends before that.
+func (c *Cursor[T]) PeekN(n int) []T {
+    if n <= 0 {
+        return []T{}
+    }
+    c.fill(n)
+    return slices.Clone(c.pending[:min(n, len(c.pending))])
+}
+
+// Unread pushes v back, so it becomes the next value returned by Next or Peek.
+// It does not have to be a value that has been read before.
+func (c *Cursor[T]) Unread(v T) {
+    c.pending = slices.Insert(c.pending, 0, v)
+}
+
+// Stop releases the underlying iterator, values that have been peeked or unread are still available afterward.
+func (c *Cursor[T]) Stop() {
+    if !c.stopped {
+        c.stopped = true
+        c.stop()
+    }
+}
+
+// Rest returns an iterator that yields the remaining values starting from the current position.
+// Breaking out of the loop keeps the cursor right after the last yielded value, so you can keep using the cursor afterward.
+func (c *Cursor[T]) Rest() Iterator[T] {
+    return func(yield func(T) bool) {
+        for {
+            v, ok := c.Next()
+            if !ok {
+                return
+            }
+            if !yield(v) {
+                return
+            }
+        }
+    }
+}
+
+func (c *Cursor[T]) fill(n int) bool {
+    for len(c.pending) < n {
+        v, ok := c.pull()
+        if !ok {
+            return false
+        }
+        c.pending = append(c.pending, v)
+    }
+    return true
+}
+
+func (c *Cursor[T]) pull() (T, bool) {
+    if c.stopped {
+        var zero T
+        return zero, false
+    }
+    v, ok := c.next()
+    if !ok {
+        c.Stop()
+    }
+    return v, ok
+}
+
+// Cursor2 is the iter.Seq2 version of Cursor.
+type Cursor2[T1, T2 any] struct {
+    c *Cursor[*Combined[T1, T2]]
+}
+
+// NewCursor2 is the iter.Seq2 version of NewCursor function.
+func NewCursor2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter) *Cursor2[T1, T2] {
+    return &Cursor2[T1, T2]{
+        c: NewCursor(Combine(iterator)),
+    }
+}
+
+// Next consumes and returns the next 2-tuple, the third return value is false if there are no more values.
+func (c *Cursor2[T1, T2]) Next() (T1, T2, bool) {
+    return unpackCombined(c.c.Next())
+}
+
+// Peek returns the next 2-tuple without consuming it, the third return value is false if there are no more values.
+func (c *Cursor2[T1, T2]) Peek() (T1, T2, bool) {
+    return unpackCombined(c.c.Peek())
+}
+
+// PeekN returns up to n next 2-tuples without consuming them, fewer values are returned if the iterator ends before that.
+func (c *Cursor2[T1, T2]) PeekN(n int) []*Combined[T1, T2] {
+    return c.c.PeekN(n)
+}
+
+// Unread pushes the 2-tuple (v1, v2) back, so it becomes the next value returned by Next or Peek.
+func (c *Cursor2[T1, T2]) Unread(v1 T1, v2 T2) {
+    c.c.Unread(Combiner(v1, v2))
+}
+
+// Stop releases the underlying iterator.
+func (c *Cursor2[T1, T2]) Stop() {
+    c.c.Stop()
+}
+
+// Rest returns an iterator that yields the remaining 2-tuples starting from the current position.
+func (c *Cursor2[T1, T2]) Rest() Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        for v := range c.c.Rest() {
+            if !yield(v.V1, v.V2) {
+                return
+            }
+        }
+    }
+}
+
+func unpackCombined[T1, T2 any](v *Combined[T1, T2], ok bool) (T1, T2, bool) {
+    if !ok {
+        var zero1 T1
+        var zero2 T2
+        return zero1, zero2, false
+    }
+    return v.V1, v.V2, true
+}
@@ -0,0 +1,139 @@
+package goiter
+
+import (
+    "fmt"
+    "slices"
+    "testing"
+)
+
+func TestCursor(t *testing.T) {
+    c := NewCursor(Items(1, 2, 3, 4, 5))
+    defer c.Stop()
+
+    if v, ok := c.Peek(); !ok || v != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1 true, actual: %v %v", v, ok))
+    }
+    if v, ok := c.Next(); !ok || v != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1 true, actual: %v %v", v, ok))
+    }
+
+    peeked := c.PeekN(3)
+    expect := []int{2, 3, 4}
+    if !slices.Equal(expect, peeked) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, peeked))
+    }
+    if v, ok := c.Next(); !ok || v != 2 {
+        t.Fatal(fmt.Sprintf("expect: 2 true, actual: %v %v", v, ok))
+    }
+
+    c.Unread(2)
+    c.Unread(100)
+    peeked = c.PeekN(10)
+    expect = []int{100, 2, 3, 4, 5}
+    if !slices.Equal(expect, peeked) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, peeked))
+    }
+
+    actual := []int{}
+    for v := range c.Rest() {
+        actual = append(actual, v)
+        if v == 3 {
+            break
+        }
+    }
+    expect = []int{100, 2, 3}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = []int{}
+    for v := range c.Rest() {
+        actual = append(actual, v)
+    }
+    expect = []int{4, 5}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if v, ok := c.Next(); ok {
+        t.Fatal(fmt.Sprintf("expect no more values, actual: %v", v))
+    }
+    if _, ok := c.Peek(); ok {
+        t.Fatal("expect no more values")
+    }
+    if peeked := c.PeekN(0); len(peeked) != 0 {
+        t.Fatal(fmt.Sprintf("expect: [], actual: %v", peeked))
+    }
+
+    // unread still works after the cursor is exhausted
+    c.Unread(6)
+    if v, ok := c.Next(); !ok || v != 6 {
+        t.Fatal(fmt.Sprintf("expect: 6 true, actual: %v %v", v, ok))
+    }
+}
+
+func TestCursor_Stop(t *testing.T) {
+    c := NewCursor(Counter(0))
+    c.PeekN(2)
+    c.Stop()
+    c.Stop()
+
+    actual := []int{}
+    for v := range c.Rest() {
+        actual = append(actual, v)
+    }
+    expect := []int{0, 1}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+}
+
+func TestCursor2(t *testing.T) {
+    c := NewCursor2(Slice([]string{"a", "b", "c"}))
+    defer c.Stop()
+
+    if idx, v, ok := c.Peek(); !ok || idx != 0 || v != "a" {
+        t.Fatal(fmt.Sprintf("expect: 0 a true, actual: %v %v %v", idx, v, ok))
+    }
+    if idx, v, ok := c.Next(); !ok || idx != 0 || v != "a" {
+        t.Fatal(fmt.Sprintf("expect: 0 a true, actual: %v %v %v", idx, v, ok))
+    }
+    peeked := c.PeekN(5)
+    if len(peeked) != 2 || peeked[0].V2 != "b" || peeked[1].V2 != "c" {
+        t.Fatal(fmt.Sprintf("unexpected peeked values: %v", peeked))
+    }
+
+    c.Unread(-1, "z")
+    actualIdx := []int{}
+    actualVal := []string{}
+    for idx, v := range c.Rest() {
+        actualIdx = append(actualIdx, idx)
+        actualVal = append(actualVal, v)
+    }
+    expectIdx := []int{-1, 1, 2}
+    expectVal := []string{"z", "b", "c"}
+    if !slices.Equal(expectIdx, actualIdx) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectIdx, actualIdx))
+    }
+    if !slices.Equal(expectVal, actualVal) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectVal, actualVal))
+    }
+
+    if _, _, ok := c.Next(); ok {
+        t.Fatal("expect no more values")
+    }
+    if _, _, ok := c.Peek(); ok {
+        t.Fatal("expect no more values")
+    }
+
+    c = NewCursor2(Slice([]string{"a", "b", "c"}))
+    for _, v := range c.Rest() {
+        if v == "b" {
+            break
+        }
+    }
+    if _, v, ok := c.Next(); !ok || v != "c" {
+        t.Fatal(fmt.Sprintf("expect: c true, actual: %v %v", v, ok))
+    }
+    c.Stop()
+}
@@ -0,0 +1,147 @@
+package goiter
+
+import (
+    "cmp"
+    "fmt"
+    "iter"
+    "runtime"
+    "runtime/debug"
+    "slices"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// PullInfo describes an iter.Pull coroutine created by a goiter operator that is still alive, it is reported by LivePulls.
+type PullInfo struct {
+    // ID tells the order in which the coroutines were created.
+    ID uint64
+    // Creator is the name of the function that created the coroutine, such as "github.com/hsldymq/goiter.Zip[...].func1".
+    Creator string
+    // Stack is the stack trace of the goroutine that created the coroutine.
+    Stack []byte
+    // Created is the time when the coroutine was created.
+    Created time.Time
+}
+
+func (p PullInfo) String() string {
+    return fmt.Sprintf("pull #%d created by %s at %s\n%s", p.ID, p.Creator, p.Created.Format(time.RFC3339Nano), p.Stack)
+}
+
+var (
+    debugEnabled atomic.Bool
+    pullRegistry = struct {
+        sync.Mutex
+        lastID uint64
+        live   map[uint64]*PullInfo
+    }{live: map[uint64]*PullInfo{}}
+)
+
+// SetDebug turns the debug mode on or off, it is off by default.
+// In debug mode, goiter keeps track of the iter.Pull coroutines created by its operators, such as Zip, ZipAs, FinishOnce and Cursor,
+// until they are stopped or exhausted, so that leaked coroutines can be found by LivePulls.
+// Tracking records the stack trace of each coroutine, which is expensive, so it is meant for tests only.
+// For example:
+//
+//  func TestMain(m *testing.M) {
+//      goiter.SetDebug(true)
+//      code := m.Run()
+//      for _, p := range goiter.LivePulls() {
+//          fmt.Println("leaked", p)
+//          code = 1
+//      }
+//      os.Exit(code)
+//  }
+func SetDebug(enabled bool) {
+    debugEnabled.Store(enabled)
+}
+
+// LivePulls returns the iter.Pull coroutines created in debug mode that have neither been stopped nor exhausted, in the order of creation.
+// A coroutine that is alive after the iteration is over is leaked, its goroutine will never exit.
+// It returns nothing if debug mode has never been turned on, see SetDebug.
+func LivePulls() []PullInfo {
+    pullRegistry.Lock()
+    defer pullRegistry.Unlock()
+
+    result := make([]PullInfo, 0, len(pullRegistry.live))
+    for _, p := range pullRegistry.live {
+        result = append(result, *p)
+    }
+    slices.SortFunc(result, func(a, b PullInfo) int {
+        return cmp.Compare(a.ID, b.ID)
+    })
+    return result
+}
+
+// pull is iter.Pull that is tracked in debug mode, every operator should call it instead of iter.Pull.
+func pull[T any](seq iter.Seq[T]) (func() (T, bool), func()) {
+    next, stop := iter.Pull(seq)
+    if !debugEnabled.Load() {
+        return next, stop
+    }
+
+    id := trackPull()
+    trackedNext := func() (T, bool) {
+        v, ok := next()
+        if !ok {
+            untrackPull(id)
+        }
+        return v, ok
+    }
+    trackedStop := func() {
+        stop()
+        untrackPull(id)
+    }
+    return trackedNext, trackedStop
+}
+
+// pull2 is iter.Pull2 that is tracked in debug mode, every operator should call it instead of iter.Pull2.
+func pull2[T1, T2 any](seq iter.Seq2[T1, T2]) (func() (T1, T2, bool), func()) {
+    next, stop := iter.Pull2(seq)
+    if !debugEnabled.Load() {
+        return next, stop
+    }
+
+    id := trackPull()
+    trackedNext := func() (T1, T2, bool) {
+        v1, v2, ok := next()
+        if !ok {
+            untrackPull(id)
+        }
+        return v1, v2, ok
+    }
+    trackedStop := func() {
+        stop()
+        untrackPull(id)
+    }
+    return trackedNext, trackedStop
+}
+
+// trackPull must be called directly by pull or pull2, so that the operator calling them is recorded as the creator.
+func trackPull() uint64 {
+    creator := "unknown"
+    // skip trackPull and pull
+    if pc, _, _, ok := runtime.Caller(2); ok {
+        if f := runtime.FuncForPC(pc); f != nil {
+            creator = f.Name()
+        }
+    }
+    info := &PullInfo{
+        Creator: creator,
+        Stack:   debug.Stack(),
+        Created: time.Now(),
+    }
+
+    pullRegistry.Lock()
+    defer pullRegistry.Unlock()
+    pullRegistry.lastID++
+    info.ID = pullRegistry.lastID
+    pullRegistry.live[info.ID] = info
+    return info.ID
+}
+
+func untrackPull(id uint64) {
+    pullRegistry.Lock()
+    defer pullRegistry.Unlock()
+    delete(pullRegistry.live, id)
+}
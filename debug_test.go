@@ -0,0 +1,72 @@
+package goiter
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+)
+
+func TestLivePulls(t *testing.T) {
+    SetDebug(true)
+    defer SetDebug(false)
+    if n := len(LivePulls()); n != 0 {
+        t.Fatal(fmt.Sprintf("expect no live pulls at the beginning, actual: %d", n))
+    }
+
+    // stopped by breaking out of the loop
+    for _, _ = range Zip(Range(1, 3), Range(1, 3)) {
+        break
+    }
+    for _ = range ZipAs(Range(1, 3), Range(1, 3), func(z *Zipped[int, int]) int { return z.V1 }, true) {
+        break
+    }
+    if n := len(LivePulls()); n != 0 {
+        t.Fatal(fmt.Sprintf("expect no live pulls, actual: %d", n))
+    }
+
+    // a cursor is alive until it is stopped
+    c := NewCursor(Range(1, 3))
+    c.Next()
+    pulls := LivePulls()
+    if len(pulls) != 1 {
+        t.Fatal(fmt.Sprintf("expect 1 live pull, actual: %d", len(pulls)))
+    }
+    if !strings.Contains(pulls[0].Creator, "NewCursor") {
+        t.Fatal("unexpected creator:", pulls[0].Creator)
+    }
+    if !strings.Contains(string(pulls[0].Stack), "TestLivePulls") {
+        t.Fatal("expect the creation stack to be recorded")
+    }
+    if !strings.Contains(pulls[0].String(), "created by") {
+        t.Fatal("unexpected string:", pulls[0].String())
+    }
+    c.Stop()
+    if n := len(LivePulls()); n != 0 {
+        t.Fatal(fmt.Sprintf("expect no live pulls after stopping, actual: %d", n))
+    }
+
+    // or until it is exhausted
+    c2 := NewCursor(Range(1, 2))
+    for _, ok := c2.Next(); ok; _, ok = c2.Next() {
+    }
+    if n := len(LivePulls()); n != 0 {
+        t.Fatal(fmt.Sprintf("expect no live pulls after exhausting, actual: %d", n))
+    }
+
+    // pulls are listed in the order of creation
+    c3, c4 := NewCursor(Range(1, 2)), NewCursor2(Slice([]int{1}))
+    pulls = LivePulls()
+    if len(pulls) != 2 || pulls[0].ID >= pulls[1].ID {
+        t.Fatal(fmt.Sprintf("unexpected live pulls: %v", pulls))
+    }
+    c3.Stop()
+    c4.Stop()
+
+    // not tracked when debug mode is off
+    SetDebug(false)
+    c5 := NewCursor(Range(1, 2))
+    defer c5.Stop()
+    if n := len(LivePulls()); n != 0 {
+        t.Fatal(fmt.Sprintf("expect pulls not to be tracked, actual: %d", n))
+    }
+}
@@ -0,0 +1,252 @@
+package goiter
+
+import (
+    "bufio"
+    "encoding/gob"
+    "errors"
+    "io"
+    "os"
+    "slices"
+)
+
+// SpillEncoder writes elements to a temporary file, it is used by ExternalOrderBy.
+type SpillEncoder[T any] interface {
+    Encode(v T) error
+}
+
+// SpillDecoder reads back the elements written by the corresponding SpillEncoder, it should return io.EOF when there are no more elements.
+type SpillDecoder[T any] interface {
+    Decode() (T, error)
+}
+
+// SpillCodec creates encoders and decoders for the temporary files of ExternalOrderBy.
+type SpillCodec[T any] interface {
+    NewEncoder(w io.Writer) SpillEncoder[T]
+    NewDecoder(r io.Reader) SpillDecoder[T]
+}
+
+// GobCodec returns a SpillCodec based on encoding/gob, it is the default codec of ExternalOrderBy.
+// So the limitations of encoding/gob apply, for example, only exported struct fields are preserved.
+func GobCodec[T any]() SpillCodec[T] {
+    return gobCodec[T]{}
+}
+
+// ExternalOrderOptions configures ExternalOrderBy.
+type ExternalOrderOptions struct {
+    // RunSize is the maximum number of elements sorted in memory at a time, 100000 is used if it is less than or equal to 0.
+    RunSize int
+    // TempDir is the directory in which temporary files are created, the default directory for temporary files is used if it is empty.
+    TempDir string
+}
+
+const defaultExternalRunSize = 100000
+
+// ExternalOrderBy is like StableOrderBy, but it is designed for iterators that produce more data than memory can hold.
+// It sorts the elements in runs of at most opts.RunSize elements, spills every full run to a temporary file through the codec,
+// and then lazily merges the runs while yielding, so only about one run of elements is kept in memory.
+// If codec is nil, GobCodec is used, and if opts is nil, the default options are used.
+//
+// Since reading and writing files may fail, the returned iterator yields 2-tuples of (element, error).
+// When an error occurs, it yields the zero value along with the error and then stops.
+// Temporary files are removed when the iteration completes, fails, or when you break out of the loop.
+// For example:
+//
+//	for v, err := range goiter.ExternalOrderBy(hugeIterator, cmp.Compare[int], nil, &goiter.ExternalOrderOptions{RunSize: 1000000}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(v)
+//	}
+func ExternalOrderBy[TIter SeqX[T], T any](
+    iterator TIter,
+    cmp func(T, T) int,
+    codec SpillCodec[T],
+    opts *ExternalOrderOptions,
+) Iterator2[T, error] {
+    if codec == nil {
+        codec = GobCodec[T]()
+    }
+    runSize := defaultExternalRunSize
+    tempDir := ""
+    if opts != nil {
+        if opts.RunSize > 0 {
+            runSize = opts.RunSize
+        }
+        tempDir = opts.TempDir
+    }
+
+    return func(yield func(T, error) bool) {
+        var zero T
+        sorter := &externalSorter[T]{
+            cmp:     cmp,
+            codec:   codec,
+            tempDir: tempDir,
+        }
+        defer sorter.cleanup()
+
+        buffer := make([]T, 0)
+        for each := range iterator {
+            buffer = append(buffer, each)
+            if len(buffer) >= runSize {
+                if err := sorter.spill(buffer); err != nil {
+                    yield(zero, err)
+                    return
+                }
+                buffer = buffer[:0]
+            }
+        }
+        slices.SortStableFunc(buffer, cmp)
+
+        if len(sorter.runs) == 0 {
+            for _, each := range buffer {
+                if !yield(each, nil) {
+                    return
+                }
+            }
+            return
+        }
+
+        for each, err := range sorter.merge(buffer) {
+            if !yield(each, err) || err != nil {
+                return
+            }
+        }
+    }
+}
+
+type externalSorter[T any] struct {
+    cmp     func(T, T) int
+    codec   SpillCodec[T]
+    tempDir string
+    runs    []*os.File
+}
+
+type externalRunHead[T any] struct {
+    v   T
+    run int
+}
+
+func (s *externalSorter[T]) spill(buffer []T) error {
+    slices.SortStableFunc(buffer, s.cmp)
+
+    f, err := os.CreateTemp(s.tempDir, "goiter-sort-*")
+    if err != nil {
+        return err
+    }
+    s.runs = append(s.runs, f)
+
+    w := bufio.NewWriter(f)
+    enc := s.codec.NewEncoder(w)
+    for _, each := range buffer {
+        if err := enc.Encode(each); err != nil {
+            return err
+        }
+    }
+    if err := w.Flush(); err != nil {
+        return err
+    }
+    _, err = f.Seek(0, io.SeekStart)
+    return err
+}
+
+// merge performs a k-way merge of the spilled runs and the last run which is still in memory.
+// Ties are broken by the run index, and runs are created in input order, so the overall sort is stable.
+func (s *externalSorter[T]) merge(memRun []T) Iterator2[T, error] {
+    return func(yield func(T, error) bool) {
+        var zero T
+        decoders := make([]SpillDecoder[T], len(s.runs))
+        for i, f := range s.runs {
+            decoders[i] = s.codec.NewDecoder(bufio.NewReader(f))
+        }
+        memIdx := 0
+        memRunID := len(s.runs)
+        nextOf := func(run int) (T, bool, error) {
+            if run == memRunID {
+                if memIdx >= len(memRun) {
+                    return zero, false, nil
+                }
+                v := memRun[memIdx]
+                memIdx++
+                return v, true, nil
+            }
+            v, err := decoders[run].Decode()
+            if errors.Is(err, io.EOF) {
+                return zero, false, nil
+            }
+            if err != nil {
+                return zero, false, err
+            }
+            return v, true, nil
+        }
+
+        h := &kHeap[externalRunHead[T]]{
+            cmp: func(a, b externalRunHead[T]) int {
+                if c := s.cmp(a.v, b.v); c != 0 {
+                    return c
+                }
+                return a.run - b.run
+            },
+        }
+        for run := 0; run <= memRunID; run++ {
+            v, ok, err := nextOf(run)
+            if err != nil {
+                yield(zero, err)
+                return
+            }
+            if ok {
+                h.push(externalRunHead[T]{v: v, run: run})
+            }
+        }
+
+        for len(h.items) > 0 {
+            head := h.pop()
+            if !yield(head.v, nil) {
+                return
+            }
+            v, ok, err := nextOf(head.run)
+            if err != nil {
+                yield(zero, err)
+                return
+            }
+            if ok {
+                h.push(externalRunHead[T]{v: v, run: head.run})
+            }
+        }
+    }
+}
+
+func (s *externalSorter[T]) cleanup() {
+    for _, f := range s.runs {
+        _ = f.Close()
+        _ = os.Remove(f.Name())
+    }
+    s.runs = nil
+}
+
+type gobCodec[T any] struct{}
+
+func (gobCodec[T]) NewEncoder(w io.Writer) SpillEncoder[T] {
+    return gobEncoder[T]{enc: gob.NewEncoder(w)}
+}
+
+func (gobCodec[T]) NewDecoder(r io.Reader) SpillDecoder[T] {
+    return gobDecoder[T]{dec: gob.NewDecoder(r)}
+}
+
+type gobEncoder[T any] struct {
+    enc *gob.Encoder
+}
+
+func (e gobEncoder[T]) Encode(v T) error {
+    return e.enc.Encode(&v)
+}
+
+type gobDecoder[T any] struct {
+    dec *gob.Decoder
+}
+
+func (d gobDecoder[T]) Decode() (T, error) {
+    var v T
+    err := d.dec.Decode(&v)
+    return v, err
+}
@@ -0,0 +1,141 @@
+package goiter
+
+import (
+    "cmp"
+    "errors"
+    "io"
+    "math/rand/v2"
+    "os"
+    "slices"
+    "testing"
+)
+
+func TestExternalOrderBy(t *testing.T) {
+    input := make([]int, 0, 1000)
+    r := rand.New(rand.NewPCG(1, 2))
+    for range 1000 {
+        input = append(input, r.IntN(100))
+    }
+    expect := slices.Clone(input)
+    slices.Sort(expect)
+
+    // case 1: spilled to disk
+    dir := t.TempDir()
+    actual := []int{}
+    for v, err := range ExternalOrderBy(SliceElems(input), cmp.Compare[int], nil, &ExternalOrderOptions{RunSize: 64, TempDir: dir}) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, v)
+    }
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+    assertDirEmpty(t, dir)
+
+    // case 2: fits in memory
+    actual = []int{}
+    for v, err := range ExternalOrderBy(SliceElems(input), cmp.Compare[int], GobCodec[int](), nil) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, v)
+    }
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    // case 3: temporary files are removed when breaking out of the loop
+    actual = []int{}
+    for v, _ := range ExternalOrderBy(SliceElems(input), cmp.Compare[int], nil, &ExternalOrderOptions{RunSize: 100, TempDir: dir}) {
+        actual = append(actual, v)
+        if len(actual) == 10 {
+            break
+        }
+    }
+    if !slices.Equal(expect[:10], actual) {
+        t.Fatal("expect:", expect[:10], "actual:", actual)
+    }
+    assertDirEmpty(t, dir)
+
+    // case 4: empty input
+    if c := Count2(ExternalOrderBy(Empty[int](), cmp.Compare[int], nil, nil)); c != 0 {
+        t.Fatal("expect:", 0, "actual:", c)
+    }
+}
+
+func TestExternalOrderBy_Stable(t *testing.T) {
+    type person struct {
+        Name string
+        Age  int
+    }
+    input := []person{
+        {"bob", 25},
+        {"eve", 30},
+        {"alice", 25},
+        {"john", 20},
+        {"anne", 30},
+        {"mike", 25},
+    }
+    actual := []person{}
+    byAge := func(a, b person) int { return cmp.Compare(a.Age, b.Age) }
+    for v, err := range ExternalOrderBy(SliceElems(input), byAge, nil, &ExternalOrderOptions{RunSize: 2, TempDir: t.TempDir()}) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, v)
+    }
+    expect := []person{
+        {"john", 20},
+        {"bob", 25},
+        {"alice", 25},
+        {"mike", 25},
+        {"eve", 30},
+        {"anne", 30},
+    }
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+}
+
+func TestExternalOrderBy_Error(t *testing.T) {
+    dir := t.TempDir()
+    errBroken := errors.New("broken")
+    var lastErr error
+    count := 0
+    for _, err := range ExternalOrderBy(Range(1, 10), cmp.Compare[int], brokenCodec[int]{err: errBroken}, &ExternalOrderOptions{RunSize: 3, TempDir: dir}) {
+        count++
+        lastErr = err
+    }
+    if count != 1 || !errors.Is(lastErr, errBroken) {
+        t.Fatal("expect a single error, actual:", count, lastErr)
+    }
+    assertDirEmpty(t, dir)
+}
+
+type brokenCodec[T any] struct {
+    err error
+}
+
+func (c brokenCodec[T]) NewEncoder(w io.Writer) SpillEncoder[T] {
+    return GobCodec[T]().NewEncoder(w)
+}
+
+func (c brokenCodec[T]) NewDecoder(r io.Reader) SpillDecoder[T] {
+    return c
+}
+
+func (c brokenCodec[T]) Decode() (T, error) {
+    var zero T
+    return zero, c.err
+}
+
+func assertDirEmpty(t *testing.T, dir string) {
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        t.Fatal("unexpected error:", err)
+    }
+    if len(entries) != 0 {
+        t.Fatal("expect temporary files to be removed, actual:", len(entries))
+    }
+}
@@ -1,6 +1,9 @@
 package goiter
 
-import "iter"
+import (
+    "math/rand/v2"
+    "slices"
+)
 
 // Filter returns an iterator that only yields the values of the input iterator that satisfy the predicate.
 func Filter[TIter SeqX[T], T any](
@@ -8,13 +11,7 @@ func Filter[TIter SeqX[T], T any](
     predicate func(T) bool,
 ) Iterator[T] {
     return func(yield func(T) bool) {
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             if !predicate(v) {
                 continue
             }
@@ -31,13 +28,7 @@ func Filter2[TIter Seq2X[T1, T2], T1 any, T2 any](
     predicate func(T1, T2) bool,
 ) Iterator2[T1, T2] {
     return func(yield func(T1, T2) bool) {
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             if !predicate(v1, v2) {
                 continue
             }
@@ -57,13 +48,7 @@ func OfType[U any, TIter SeqX[T], T any](
     iterator TIter,
 ) Iterator[U] {
     return func(yield func(U) bool) {
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             if u, ok := any(v).(U); ok {
                 if !yield(u) {
                     return
@@ -87,14 +72,8 @@ func Take[TIter SeqX[T], T any](
     }
 
     return func(yield func(T) bool) {
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
         count := 0
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             if !yield(v) {
                 return
             }
@@ -116,14 +95,8 @@ func Take2[TIter Seq2X[T1, T2], T1, T2 any](
     }
 
     return func(yield func(T1, T2) bool) {
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
         count := 0
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             if !yield(v1, v2) {
                 return
             }
@@ -153,13 +126,7 @@ func TakeLast[TIter SeqX[T], T any](
         idxTail := -1
         buffer := make([]T, n)
 
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                break
-            }
+        for v := range iterator {
             if idxHead == -1 {
                 buffer[0] = v
                 idxHead = 0
@@ -201,26 +168,20 @@ func TakeLast2[TIter Seq2X[T1, T2], T1, T2 any](
     return func(yield func(T1, T2) bool) {
         idxHead := -1
         idxTail := -1
-        buffer := make([]*Combined[T1, T2], n)
+        buffer := make([]Combined[T1, T2], n)
 
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                break
-            }
+        for v1, v2 := range iterator {
             if idxHead == -1 {
-                buffer[0] = &Combined[T1, T2]{V1: v1, V2: v2}
+                buffer[0] = Combined[T1, T2]{V1: v1, V2: v2}
                 idxHead = 0
                 idxTail = 0
             } else if (idxHead+n-1)%n == idxTail {
                 idxTail = idxHead
                 idxHead = (idxHead + 1) % n
-                buffer[idxTail] = &Combined[T1, T2]{V1: v1, V2: v2}
+                buffer[idxTail] = Combined[T1, T2]{V1: v1, V2: v2}
             } else {
                 idxTail = (idxTail + 1) % n
-                buffer[idxTail] = &Combined[T1, T2]{V1: v1, V2: v2}
+                buffer[idxTail] = Combined[T1, T2]{V1: v1, V2: v2}
             }
         }
         if idxHead < 0 {
@@ -253,14 +214,8 @@ func Skip[TIter SeqX[T], T any](
     }
 
     return func(yield func(T) bool) {
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
         count := 0
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             count++
             if count <= n {
                 continue
@@ -282,14 +237,8 @@ func Skip2[TIter Seq2X[T1, T2], T1, T2 any](
     }
 
     return func(yield func(T1, T2) bool) {
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
         count := 0
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             count++
             if count <= n {
                 continue
@@ -319,13 +268,7 @@ func SkipLast[TIter SeqX[T], T any](
         idxTail := -1
         ringBuff := make([]T, n)
 
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                break
-            }
+        for v := range iterator {
             if idxHead == -1 {
                 ringBuff[0] = v
                 idxHead = 0
@@ -358,30 +301,24 @@ func SkipLast2[TIter Seq2X[T1, T2], T1, T2 any](
     return func(yield func(T1, T2) bool) {
         idxHead := -1
         idxTail := -1
-        ringBuff := make([]*Combined[T1, T2], n)
+        ringBuff := make([]Combined[T1, T2], n)
 
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                break
-            }
+        for v1, v2 := range iterator {
             if idxHead == -1 {
-                ringBuff[0] = &Combined[T1, T2]{V1: v1, V2: v2}
+                ringBuff[0] = Combined[T1, T2]{V1: v1, V2: v2}
                 idxHead = 0
                 idxTail = 0
             } else if (idxHead+n-1)%n == idxTail {
                 yieldVal := ringBuff[idxHead]
                 idxTail = idxHead
                 idxHead = (idxHead + 1) % n
-                ringBuff[idxTail] = &Combined[T1, T2]{V1: v1, V2: v2}
+                ringBuff[idxTail] = Combined[T1, T2]{V1: v1, V2: v2}
                 if !yield(yieldVal.V1, yieldVal.V2) {
                     return
                 }
             } else {
                 idxTail = (idxTail + 1) % n
-                ringBuff[idxTail] = &Combined[T1, T2]{V1: v1, V2: v2}
+                ringBuff[idxTail] = Combined[T1, T2]{V1: v1, V2: v2}
             }
         }
     }
@@ -397,13 +334,7 @@ func Distinct[TIter SeqX[T], T comparable](iterator TIter) Iterator[T] {
     return func(yield func(T) bool) {
         yielded := map[any]bool{}
 
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             if yielded[v] {
                 continue
             }
@@ -426,13 +357,7 @@ func DistinctV1[TIter Seq2X[T1, T2], T1 comparable, T2 any](iterator TIter) Iter
     return func(yield func(T1, T2) bool) {
         yielded := newDistinctor[T1]()
 
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             if !yielded.mark(v1) {
                 continue
             }
@@ -449,13 +374,7 @@ func DistinctV2[TIter Seq2X[T1, T2], T1 any, T2 comparable](iterator TIter) Iter
     return func(yield func(T1, T2) bool) {
         yielded := newDistinctor[T2]()
 
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             if !yielded.mark(v2) {
                 continue
             }
@@ -475,13 +394,7 @@ func DistinctBy[TIter SeqX[T], T any, K comparable](
     return func(yield func(T) bool) {
         yielded := newDistinctor[K]()
 
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             if !yielded.mark(keySelector(v)) {
                 continue
             }
@@ -501,13 +414,7 @@ func Distinct2By[TIter Seq2X[T1, T2], T1 any, T2 any, K comparable](
     return func(yield func(T1, T2) bool) {
         yielded := newDistinctor[K]()
 
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             if !yielded.mark(keySelector(v1, v2)) {
                 continue
             }
@@ -518,6 +425,75 @@ func Distinct2By[TIter Seq2X[T1, T2], T1 any, T2 any, K comparable](
     }
 }
 
+// Sample returns an iterator that yields k elements randomly chosen from the input iterator, every element has the same chance of being chosen.
+// It uses reservoir sampling, so only k elements are kept in memory no matter how many elements the input iterator yields,
+// and the chosen elements are yielded in the order they appear in the input iterator.
+// If the input iterator yields less than k elements, all of them are yielded.
+// rng works the same as in Shuffle.
+// For example:
+//
+//	rng := rand.New(rand.NewPCG(1, 2))
+//	preview := goiter.Sample(rows, 10, rng) // yields 10 random rows, and the same 10 rows for the first iteration every time with the same seed
+func Sample[TIter SeqX[T], T any](
+    iterator TIter,
+    k int,
+    rng *rand.Rand,
+) Iterator[T] {
+    if k <= 0 {
+        return Empty[T]()
+    }
+    return func(yield func(T) bool) {
+        r := iterationRand(rng)
+        reservoir := make([]Combined[int, T], 0, k)
+        idx := 0
+        for v := range iterator {
+            if idx < k {
+                reservoir = append(reservoir, Combined[int, T]{V1: idx, V2: v})
+            } else if j := r.IntN(idx + 1); j < k {
+                reservoir[j] = Combined[int, T]{V1: idx, V2: v}
+            }
+            idx++
+        }
+
+        slices.SortFunc(reservoir, func(a, b Combined[int, T]) int {
+            return a.V1 - b.V1
+        })
+        for _, each := range reservoir {
+            if !yield(each.V2) {
+                return
+            }
+        }
+    }
+}
+
+// SampleFraction returns an iterator that yields each element of the input iterator independently with probability p,
+// so it yields about p of all the elements, it does not buffer anything.
+// If p is less than or equal to 0, it yields nothing, and if p is greater than or equal to 1, it yields everything.
+// rng works the same as in Shuffle.
+func SampleFraction[TIter SeqX[T], T any](
+    iterator TIter,
+    p float64,
+    rng *rand.Rand,
+) Iterator[T] {
+    if p <= 0 {
+        return Empty[T]()
+    }
+    if p >= 1 {
+        return Iterator[T](iterator)
+    }
+    return func(yield func(T) bool) {
+        r := iterationRand(rng)
+        for v := range iterator {
+            if r.Float64() >= p {
+                continue
+            }
+            if !yield(v) {
+                return
+            }
+        }
+    }
+}
+
 func newDistinctor[T comparable]() *distinctor[T] {
     return &distinctor[T]{
         dm: map[T]bool{},
@@ -3,6 +3,7 @@ package goiter
 import (
     "fmt"
     "maps"
+    "math/rand/v2"
     "slices"
     "testing"
 )
@@ -647,3 +648,87 @@ func TestDistinctBy2(t *testing.T) {
         break
     }
 }
+
+func TestSample(t *testing.T) {
+    actual1 := []int{}
+    for v := range Sample(Range(1, 1000), 10, rand.New(rand.NewPCG(1, 2))) {
+        actual1 = append(actual1, v)
+    }
+    actual2 := []int{}
+    for v := range Sample(Range(1, 1000), 10, rand.New(rand.NewPCG(1, 2))) {
+        actual2 = append(actual2, v)
+    }
+    if len(actual1) != 10 {
+        t.Fatal(fmt.Sprintf("expect 10 values, actual: %v", actual1))
+    }
+    if !slices.Equal(actual1, actual2) {
+        t.Fatal(fmt.Sprintf("expect the same seed to produce the same result: %v, %v", actual1, actual2))
+    }
+    if !slices.IsSorted(actual1) {
+        t.Fatal(fmt.Sprintf("expect the values to keep their input order: %v", actual1))
+    }
+    if len(slices.Compact(slices.Clone(actual1))) != 10 {
+        t.Fatal(fmt.Sprintf("expect distinct values: %v", actual1))
+    }
+
+    // fewer elements than k
+    actual := []int{}
+    for v := range Sample(Items(3, 1, 2), 5, nil) {
+        actual = append(actual, v)
+    }
+    expect := []int{3, 1, 2}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(Sample(Items(1, 2, 3), 0, nil)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    for _ = range Sample(Items(1, 2, 3), 2, nil) {
+        break
+    }
+
+    // every element has the same chance of being chosen
+    counts := make([]int, 10)
+    rng := rand.New(rand.NewPCG(3, 4))
+    for range 10000 {
+        for v := range Sample(Range(0, 9), 3, rng) {
+            counts[v]++
+        }
+    }
+    for _, c := range counts {
+        if c < 2700 || c > 3300 {
+            t.Fatal(fmt.Sprintf("expect a uniform distribution, actual: %v", counts))
+        }
+    }
+}
+
+func TestSampleFraction(t *testing.T) {
+    rng := rand.New(rand.NewPCG(1, 2))
+    c := Count(SampleFraction(Range(1, 10000), 0.3, rng))
+    if c < 2800 || c > 3200 {
+        t.Fatal(fmt.Sprintf("expect about 3000 values, actual: %d", c))
+    }
+
+    actual1 := []int{}
+    for v := range SampleFraction(Range(1, 100), 0.5, rand.New(rand.NewPCG(5, 6))) {
+        actual1 = append(actual1, v)
+    }
+    actual2 := []int{}
+    for v := range SampleFraction(Range(1, 100), 0.5, rand.New(rand.NewPCG(5, 6))) {
+        actual2 = append(actual2, v)
+    }
+    if !slices.Equal(actual1, actual2) {
+        t.Fatal(fmt.Sprintf("expect the same seed to produce the same result: %v, %v", actual1, actual2))
+    }
+
+    if c := Count(SampleFraction(Range(1, 100), 0, nil)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(SampleFraction(Range(1, 100), 1, nil)); c != 100 {
+        t.Fatal(fmt.Sprintf("expect: 100, actual: %d", c))
+    }
+    for _ = range SampleFraction(Range(1, 100), 0.5, nil) {
+        break
+    }
+}
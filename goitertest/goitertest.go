@@ -0,0 +1,298 @@
+// Package goitertest provides assertions for testing iterators, whether they are built with goiter or written by hand.
+package goitertest
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "reflect"
+    "runtime"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/hsldymq/goiter"
+)
+
+var update = flag.Bool("goitertest.update", false, "rewrite the golden files of AssertGolden and AssertGolden2 with the actual output")
+
+// LeakTimeout is how long AssertEarlyBreakSafe waits for the goroutines started by the iterator to exit before reporting a leak.
+var LeakTimeout = time.Second
+
+// AssertYields iterates over iterator and reports an error if it does not yield exactly the values of want in order.
+// Values are compared by reflect.DeepEqual.
+// For example:
+//
+//  goitertest.AssertYields(t, goiter.Range(1, 3), 1, 2, 3)
+func AssertYields[TIter goiter.SeqX[T], T any](t testing.TB, iterator TIter, want ...T) {
+    t.Helper()
+    actual := collect(iterator)
+    if !equalValues(want, actual) {
+        t.Errorf("goitertest: expect yields: %v, actual: %v", want, actual)
+    }
+}
+
+// AssertYields2 is the iter.Seq2 version of AssertYields function, the expected 2-tuples can be created by goiter.Combiner.
+// For example:
+//
+//  goitertest.AssertYields2(t, goiter.Slice([]string{"a", "b"}), goiter.Combiner(0, "a"), goiter.Combiner(1, "b"))
+func AssertYields2[TIter goiter.Seq2X[T1, T2], T1, T2 any](t testing.TB, iterator TIter, want ...*goiter.Combined[T1, T2]) {
+    t.Helper()
+    wantValues := make([]goiter.Combined[T1, T2], 0, len(want))
+    for _, w := range want {
+        wantValues = append(wantValues, *w)
+    }
+    actual := collect2(iterator)
+    if !equalValues(wantValues, actual) {
+        t.Errorf("goitertest: expect yields: %v, actual: %v", wantValues, actual)
+    }
+}
+
+// AssertEarlyBreakSafe checks that iterator behaves when the consumer breaks out of the loop.
+// It iterates over iterator once to count its values, then iterates over it again for every position, breaking out of the loop at that position,
+// and reports an error if iterator calls yield again after yield has returned false, or if goroutines started by iterator are still running after the iteration.
+// iterator must be finite and reusable. The goroutine check counts all goroutines of the process, so do not use it in parallel tests.
+func AssertEarlyBreakSafe[TIter goiter.SeqX[T], T any](t testing.TB, iterator TIter) {
+    t.Helper()
+    assertEarlyBreakSafe(t, func(onValue func() bool) {
+        iterator(func(T) bool {
+            return onValue()
+        })
+    })
+}
+
+// AssertEarlyBreakSafe2 is the iter.Seq2 version of AssertEarlyBreakSafe function.
+func AssertEarlyBreakSafe2[TIter goiter.Seq2X[T1, T2], T1, T2 any](t testing.TB, iterator TIter) {
+    t.Helper()
+    assertEarlyBreakSafe(t, func(onValue func() bool) {
+        iterator(func(T1, T2) bool {
+            return onValue()
+        })
+    })
+}
+
+// AssertReusable checks that iterator can be iterated over multiple times, and that each iteration yields the same values from the beginning,
+// including an iteration that follows a broken one, as most iterators created by goiter do.
+func AssertReusable[TIter goiter.SeqX[T], T any](t testing.TB, iterator TIter) {
+    t.Helper()
+    first := collect(iterator)
+    for v := range iterator {
+        _ = v
+        break
+    }
+    if second := collect(iterator); !equalValues(first, second) {
+        t.Errorf("goitertest: expect the iterator to be reusable, first iteration yields: %v, next iteration yields: %v", first, second)
+    }
+}
+
+// AssertReusable2 is the iter.Seq2 version of AssertReusable function.
+func AssertReusable2[TIter goiter.Seq2X[T1, T2], T1, T2 any](t testing.TB, iterator TIter) {
+    t.Helper()
+    first := collect2(iterator)
+    for v1, v2 := range iterator {
+        _, _ = v1, v2
+        break
+    }
+    if second := collect2(iterator); !equalValues(first, second) {
+        t.Errorf("goitertest: expect the iterator to be reusable, first iteration yields: %v, next iteration yields: %v", first, second)
+    }
+}
+
+// AssertSingleUse checks that iterator can only be iterated over once, as the iterators created by goiter.Once do:
+// after the first iteration, even if it is broken out of after the first value, subsequent iterations yield nothing.
+// iterator must not be empty.
+func AssertSingleUse[TIter goiter.SeqX[T], T any](t testing.TB, iterator TIter) {
+    t.Helper()
+    started := false
+    for v := range iterator {
+        _ = v
+        started = true
+        break
+    }
+    if !started {
+        t.Errorf("goitertest: expect the iterator to yield values in the first iteration")
+        return
+    }
+    if rest := collect(iterator); len(rest) > 0 {
+        t.Errorf("goitertest: expect the iterator to be single use, next iteration yields: %v", rest)
+    }
+}
+
+// AssertSingleUse2 is the iter.Seq2 version of AssertSingleUse function.
+func AssertSingleUse2[TIter goiter.Seq2X[T1, T2], T1, T2 any](t testing.TB, iterator TIter) {
+    t.Helper()
+    started := false
+    for v1, v2 := range iterator {
+        _, _ = v1, v2
+        started = true
+        break
+    }
+    if !started {
+        t.Errorf("goitertest: expect the iterator to yield values in the first iteration")
+        return
+    }
+    if rest := collect2(iterator); len(rest) > 0 {
+        t.Errorf("goitertest: expect the iterator to be single use, next iteration yields: %v", rest)
+    }
+}
+
+// AssertResumable checks that iterator resumes from where the previous iteration was broken out of, as the iterators created by goiter.FinishOnce do,
+// and that it yields nothing once all values have been yielded. want is the values that the iterator yields in total.
+// It breaks out of the loop after every value, so iterator must be fresh.
+func AssertResumable[TIter goiter.SeqX[T], T any](t testing.TB, iterator TIter, want ...T) {
+    t.Helper()
+    actual := make([]T, 0, len(want))
+    for range len(want) + 1 {
+        for v := range iterator {
+            actual = append(actual, v)
+            break
+        }
+    }
+    if !equalValues(want, actual) {
+        t.Errorf("goitertest: expect the iterator to resume and yield: %v, actual: %v", want, actual)
+    }
+}
+
+// AssertNoLivePulls reports an error for each iter.Pull coroutine created by goiter operators that is still alive, along with its creation stack.
+// It only sees the coroutines created in debug mode, so turn it on by goiter.SetDebug before the code under test runs.
+// For example:
+//
+//  goiter.SetDebug(true)
+//  defer goiter.SetDebug(false)
+//  t.Cleanup(func() { goitertest.AssertNoLivePulls(t) })
+func AssertNoLivePulls(t testing.TB) {
+    t.Helper()
+    for _, p := range goiter.LivePulls() {
+        t.Errorf("goitertest: leaked %s", p)
+    }
+}
+
+// AssertGolden compares the values yielded by iterator, one per line formatted by %v, with the golden file testdata/<name>.golden.
+// Run the tests with the -goitertest.update flag to create or rewrite the golden file with the actual output.
+// For example:
+//
+//  goitertest.AssertGolden(t, goiter.Range(1, 3), "range")  // compares with testdata/range.golden, which contains "1\n2\n3\n"
+func AssertGolden[TIter goiter.SeqX[T], T any](t testing.TB, iterator TIter, name string) {
+    t.Helper()
+    b := &strings.Builder{}
+    for v := range iterator {
+        _, _ = fmt.Fprintf(b, "%v\n", v)
+    }
+    assertGolden(t, b.String(), name)
+}
+
+// AssertGolden2 is the iter.Seq2 version of AssertGolden function, each 2-tuple is written as a line of the two values separated by a tab.
+func AssertGolden2[TIter goiter.Seq2X[T1, T2], T1, T2 any](t testing.TB, iterator TIter, name string) {
+    t.Helper()
+    b := &strings.Builder{}
+    for v1, v2 := range iterator {
+        _, _ = fmt.Fprintf(b, "%v\t%v\n", v1, v2)
+    }
+    assertGolden(t, b.String(), name)
+}
+
+func assertGolden(t testing.TB, actual string, name string) {
+    t.Helper()
+    path := filepath.Join("testdata", name+".golden")
+    if *update {
+        if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+            t.Fatalf("goitertest: %v", err)
+        }
+        if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+            t.Fatalf("goitertest: %v", err)
+        }
+        return
+    }
+
+    expect, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("goitertest: %v, run the tests with -goitertest.update to create it", err)
+    }
+    if string(expect) != actual {
+        t.Errorf("goitertest: output does not match %s\nexpect:\n%s\nactual:\n%s", path, expect, actual)
+    }
+}
+
+// assertEarlyBreakSafe runs the iteration by run, which must call onValue directly from the yield function for each value.
+func assertEarlyBreakSafe(t testing.TB, run func(onValue func() bool)) {
+    t.Helper()
+    total := 0
+    run(func() bool {
+        total++
+        return true
+    })
+
+    for pos := range total {
+        goroutines := runtime.NumGoroutine()
+        count := 0
+        stopped := false
+        var extraAt string
+        run(func() bool {
+            if stopped {
+                if extraAt == "" {
+                    // skip onValue and the yield function
+                    if _, file, line, ok := runtime.Caller(2); ok {
+                        extraAt = file + ":" + strconv.Itoa(line)
+                    } else {
+                        extraAt = "unknown location"
+                    }
+                }
+                return false
+            }
+            count++
+            if count > pos {
+                stopped = true
+                return false
+            }
+            return true
+        })
+        if extraAt != "" {
+            t.Errorf("goitertest: after breaking at position %d, the iterator yields again at %s", pos, extraAt)
+        }
+        if n := waitGoroutines(goroutines); n > goroutines {
+            t.Errorf("goitertest: after breaking at position %d, %d goroutines are leaked", pos, n-goroutines)
+        }
+    }
+}
+
+// waitGoroutines waits until the number of goroutines goes down to n or LeakTimeout elapses, and returns the last number of goroutines.
+func waitGoroutines(n int) int {
+    deadline := time.Now().Add(LeakTimeout)
+    for {
+        current := runtime.NumGoroutine()
+        if current <= n || time.Now().After(deadline) {
+            return current
+        }
+        time.Sleep(time.Millisecond)
+    }
+}
+
+func collect[TIter goiter.SeqX[T], T any](iterator TIter) []T {
+    result := []T{}
+    for v := range iterator {
+        result = append(result, v)
+    }
+    return result
+}
+
+func collect2[TIter goiter.Seq2X[T1, T2], T1, T2 any](iterator TIter) []goiter.Combined[T1, T2] {
+    result := []goiter.Combined[T1, T2]{}
+    for v1, v2 := range iterator {
+        result = append(result, goiter.Combined[T1, T2]{V1: v1, V2: v2})
+    }
+    return result
+}
+
+func equalValues[T any](a, b []T) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if !reflect.DeepEqual(a[i], b[i]) {
+            return false
+        }
+    }
+    return true
+}
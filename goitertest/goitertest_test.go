@@ -0,0 +1,203 @@
+package goitertest
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/hsldymq/goiter"
+)
+
+func TestAssertYields(t *testing.T) {
+    AssertYields(t, goiter.Range(1, 3), 1, 2, 3)
+    AssertYields(t, goiter.Empty[[]int]())
+    AssertYields(t, goiter.Items([]int{1}, nil), []int{1}, nil)
+    AssertYields2(t, goiter.Slice([]string{"a", "b"}), goiter.Combiner(0, "a"), goiter.Combiner(1, "b"))
+
+    expectFailure(t, "expect yields: [1 2], actual: [1 2 3]", func(tb testing.TB) {
+        AssertYields(tb, goiter.Range(1, 3), 1, 2)
+    })
+    expectFailure(t, "expect yields: [{0 a}], actual: [{0 b}]", func(tb testing.TB) {
+        AssertYields2(tb, goiter.Slice([]string{"b"}), goiter.Combiner(0, "a"))
+    })
+}
+
+func TestAssertEarlyBreakSafe(t *testing.T) {
+    AssertEarlyBreakSafe(t, goiter.Range(1, 5))
+    AssertEarlyBreakSafe(t, goiter.Combine(goiter.Zip(goiter.Range(1, 5), goiter.Counter(0))))
+    AssertEarlyBreakSafe2(t, goiter.Slice([]int{1, 2, 3}))
+
+    ignoring := goiter.Iterator[int](func(yield func(int) bool) {
+        yield(1)
+        yield(2)
+    })
+    expectFailure(t, "after breaking at position 0, the iterator yields again at", func(tb testing.TB) {
+        AssertEarlyBreakSafe(tb, ignoring)
+    })
+    expectFailure(t, "goitertest_test.go", func(tb testing.TB) {
+        AssertEarlyBreakSafe(tb, ignoring)
+    })
+
+    ignoring2 := goiter.Iterator2[int, int](func(yield func(int, int) bool) {
+        yield(1, 1)
+        yield(2, 2)
+    })
+    expectFailure(t, "the iterator yields again", func(tb testing.TB) {
+        AssertEarlyBreakSafe2(tb, ignoring2)
+    })
+
+    // the producer goroutine is never told to stop, it is blocked until the end of the test
+    release := make(chan struct{})
+    defer close(release)
+    leaking := goiter.Iterator[int](func(yield func(int) bool) {
+        ch := make(chan int)
+        go func() {
+            defer close(ch)
+            for i := range 3 {
+                select {
+                case ch <- i:
+                case <-release:
+                    return
+                }
+            }
+        }()
+        for v := range ch {
+            if !yield(v) {
+                return
+            }
+        }
+    })
+    defer func(timeout time.Duration) { LeakTimeout = timeout }(LeakTimeout)
+    LeakTimeout = 10 * time.Millisecond
+    expectFailure(t, "after breaking at position 0, 1 goroutines are leaked", func(tb testing.TB) {
+        AssertEarlyBreakSafe(tb, leaking)
+    })
+}
+
+func TestAssertReusable(t *testing.T) {
+    AssertReusable(t, goiter.Range(1, 3))
+    AssertReusable2(t, goiter.Slice([]int{1, 2}))
+
+    expectFailure(t, "expect the iterator to be reusable", func(tb testing.TB) {
+        AssertReusable(tb, goiter.Once(goiter.Range(1, 3)))
+    })
+    expectFailure(t, "expect the iterator to be reusable", func(tb testing.TB) {
+        AssertReusable(tb, goiter.FinishOnce(goiter.Range(1, 3)))
+    })
+    expectFailure(t, "expect the iterator to be reusable", func(tb testing.TB) {
+        AssertReusable2(tb, goiter.Once2(goiter.Slice([]int{1, 2})))
+    })
+}
+
+func TestAssertSingleUse(t *testing.T) {
+    AssertSingleUse(t, goiter.Once(goiter.Range(1, 3)))
+    AssertSingleUse2(t, goiter.Once2(goiter.Slice([]int{1, 2})))
+
+    expectFailure(t, "expect the iterator to be single use, next iteration yields: [1 2 3]", func(tb testing.TB) {
+        AssertSingleUse(tb, goiter.Range(1, 3))
+    })
+    expectFailure(t, "expect the iterator to yield values in the first iteration", func(tb testing.TB) {
+        AssertSingleUse(tb, goiter.Empty[int]())
+    })
+    expectFailure(t, "expect the iterator to be single use", func(tb testing.TB) {
+        AssertSingleUse2(tb, goiter.Slice([]int{1, 2}))
+    })
+}
+
+func TestAssertResumable(t *testing.T) {
+    AssertResumable(t, goiter.FinishOnce(goiter.Range(1, 3)), 1, 2, 3)
+
+    expectFailure(t, "expect the iterator to resume and yield: [1 2 3], actual: [1 1 1 1]", func(tb testing.TB) {
+        AssertResumable(tb, goiter.Range(1, 3), 1, 2, 3)
+    })
+    expectFailure(t, "actual: [1]", func(tb testing.TB) {
+        AssertResumable(tb, goiter.Once(goiter.Range(1, 3)), 1, 2, 3)
+    })
+}
+
+func TestAssertNoLivePulls(t *testing.T) {
+    goiter.SetDebug(true)
+    defer goiter.SetDebug(false)
+
+    for _, _ = range goiter.Zip(goiter.Range(1, 3), goiter.Range(1, 3)) {
+        break
+    }
+    AssertNoLivePulls(t)
+
+    c := goiter.NewCursor(goiter.Range(1, 3))
+    expectFailure(t, "goitertest: leaked pull #", func(tb testing.TB) {
+        AssertNoLivePulls(tb)
+    })
+    c.Stop()
+    AssertNoLivePulls(t)
+}
+
+func TestAssertGolden(t *testing.T) {
+    AssertGolden(t, goiter.Range(1, 3), "range")
+    AssertGolden2(t, goiter.Slice([]string{"a", "b"}), "slice")
+
+    expectFailure(t, "output does not match testdata/range.golden", func(tb testing.TB) {
+        AssertGolden(tb, goiter.Range(1, 4), "range")
+    })
+    expectFailure(t, "run the tests with -goitertest.update to create it", func(tb testing.TB) {
+        AssertGolden(tb, goiter.Range(1, 4), "missing")
+    })
+
+    // updating writes the golden file
+    dir := t.TempDir()
+    wd, _ := os.Getwd()
+    if err := os.Chdir(dir); err != nil {
+        t.Fatal("unexpected error:", err)
+    }
+    defer func() { _ = os.Chdir(wd) }()
+    *update = true
+    defer func() { *update = false }()
+    AssertGolden(t, goiter.Range(1, 2), "new")
+    content, err := os.ReadFile(filepath.Join(dir, "testdata", "new.golden"))
+    if err != nil || string(content) != "1\n2\n" {
+        t.Fatal(fmt.Sprintf("unexpected golden file: %q %v", content, err))
+    }
+}
+
+// fakeTB records the failures instead of failing the test.
+type fakeTB struct {
+    testing.TB
+    failures []string
+}
+
+type fatalSignal struct{}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+    f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+    f.Errorf(format, args...)
+    panic(fatalSignal{})
+}
+
+func expectFailure(t *testing.T, message string, f func(tb testing.TB)) {
+    t.Helper()
+    tb := &fakeTB{TB: t}
+    func() {
+        defer func() {
+            if p := recover(); p != nil {
+                if _, ok := p.(fatalSignal); !ok {
+                    panic(p)
+                }
+            }
+        }()
+        f(tb)
+    }()
+    for _, failure := range tb.failures {
+        if strings.Contains(failure, message) {
+            return
+        }
+    }
+    t.Fatal(fmt.Sprintf("expect a failure containing %q, actual: %q", message, tb.failures))
+}
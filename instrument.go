@@ -0,0 +1,295 @@
+package goiter
+
+import (
+    "math"
+    "slices"
+    "sync"
+    "time"
+)
+
+// Recorder receives the measurements of the iterators wrapped by Instrument and Instrument2.
+// name is the name given to Instrument, so a Recorder can be shared by all stages of a pipeline.
+// Its methods may be called concurrently if the instrumented iterators are iterated over concurrently.
+// MemoryRecorder is an in-memory implementation, you can implement it yourself to export the measurements to a monitoring system.
+type Recorder interface {
+    // Yielded is called once for each value, after the value has been processed downstream, but not for a value whose processing panics.
+    // upstream is the time the input iterator took to produce the value,
+    // downstream is the time the rest of the pipeline, including the loop body, took to process it.
+    Yielded(name string, upstream, downstream time.Duration)
+    // Done is called once each iteration is over, elapsed is the duration of the whole iteration.
+    Done(name string, reason DoneReason, elapsed time.Duration)
+}
+
+// Instrument returns an iterator that yields the same values as the input iterator, and reports to recorder how long each value took to be produced upstream and processed downstream,
+// and when and why each iteration is over.
+// Wrapping several stages of a pipeline tells you which stage is slow, without a profiler.
+// For example:
+//
+//  recorder := goiter.NewMemoryRecorder()
+//  iterator := goiter.Instrument(readRecords(), "read", recorder).
+//      Filter(isValid).
+//      Instrument("filter", recorder)
+//  for record := range iterator {
+//      ...
+//  }
+//  stats, _ := recorder.Stats("read")
+//  fmt.Println(stats.Count, stats.Rate(), stats.Upstream.Quantile(0.99))
+func Instrument[TIter SeqX[T], T any](iterator TIter, name string, recorder Recorder) Iterator[T] {
+    return func(yield func(T) bool) {
+        m := newMeter(name, recorder)
+        OnDone(iterator, m.done)(func(v T) bool {
+            m.received()
+            ok := yield(v)
+            m.processed()
+            return ok
+        })
+    }
+}
+
+// Instrument2 is the iter.Seq2 version of Instrument function.
+func Instrument2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter, name string, recorder Recorder) Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        m := newMeter(name, recorder)
+        OnDone2(iterator, m.done)(func(v1 T1, v2 T2) bool {
+            m.received()
+            ok := yield(v1, v2)
+            m.processed()
+            return ok
+        })
+    }
+}
+
+type meter struct {
+    name     string
+    recorder Recorder
+    start    time.Time
+    // resumed is when the input iterator was resumed to produce the next value
+    resumed time.Time
+    // yielding is when the current value was handed over downstream
+    yielding time.Time
+}
+
+func newMeter(name string, recorder Recorder) *meter {
+    now := time.Now()
+    return &meter{
+        name:     name,
+        recorder: recorder,
+        start:    now,
+        resumed:  now,
+    }
+}
+
+func (m *meter) received() {
+    m.yielding = time.Now()
+}
+
+func (m *meter) processed() {
+    now := time.Now()
+    m.recorder.Yielded(m.name, m.yielding.Sub(m.resumed), now.Sub(m.yielding))
+    m.resumed = now
+}
+
+func (m *meter) done(reason DoneReason) {
+    m.recorder.Done(m.name, reason, time.Since(m.start))
+}
+
+// DefaultHistogramBounds are the upper bounds of the histogram buckets used by MemoryRecorder, from 1µs to 10s in a 1-2-5 series.
+var DefaultHistogramBounds = []time.Duration{
+    time.Microsecond, 2 * time.Microsecond, 5 * time.Microsecond,
+    10 * time.Microsecond, 20 * time.Microsecond, 50 * time.Microsecond,
+    100 * time.Microsecond, 200 * time.Microsecond, 500 * time.Microsecond,
+    time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+    10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+    100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+    time.Second, 2 * time.Second, 5 * time.Second,
+    10 * time.Second,
+}
+
+// Histogram is a distribution of durations over buckets.
+// Counts[i] is the number of durations that are not greater than Bounds[i] and greater than the previous bound,
+// and the last element of Counts counts the durations greater than all bounds, so Counts has one more element than Bounds.
+type Histogram struct {
+    Bounds []time.Duration
+    Counts []int64
+    // Count is the total number of durations.
+    Count int64
+    // Sum is the sum of all durations.
+    Sum time.Duration
+    Min time.Duration
+    Max time.Duration
+}
+
+func newHistogram(bounds []time.Duration) Histogram {
+    return Histogram{
+        Bounds: bounds,
+        Counts: make([]int64, len(bounds)+1),
+    }
+}
+
+func (h *Histogram) observe(d time.Duration) {
+    i, _ := slices.BinarySearch(h.Bounds, d)
+    h.Counts[i]++
+    if h.Count == 0 || d < h.Min {
+        h.Min = d
+    }
+    if d > h.Max {
+        h.Max = d
+    }
+    h.Count++
+    h.Sum += d
+}
+
+func (h Histogram) clone() Histogram {
+    h.Bounds = slices.Clone(h.Bounds)
+    h.Counts = slices.Clone(h.Counts)
+    return h
+}
+
+// Mean returns the average duration, or 0 if the histogram is empty.
+func (h Histogram) Mean() time.Duration {
+    if h.Count == 0 {
+        return 0
+    }
+    return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile returns an estimate of the q-quantile for q in [0, 1], that is the upper bound of the bucket where the quantile falls, capped by Max.
+// It returns 0 if the histogram is empty.
+func (h Histogram) Quantile(q float64) time.Duration {
+    if h.Count == 0 {
+        return 0
+    }
+    rank := int64(math.Ceil(q * float64(h.Count)))
+    if rank < 1 {
+        rank = 1
+    }
+    cumulative := int64(0)
+    for i, c := range h.Counts {
+        cumulative += c
+        if cumulative >= rank {
+            if i < len(h.Bounds) && h.Bounds[i] < h.Max {
+                return h.Bounds[i]
+            }
+            return h.Max
+        }
+    }
+    return h.Max
+}
+
+// StageStats is what MemoryRecorder has recorded for a name.
+type StageStats struct {
+    // Count is the number of values yielded.
+    Count int64
+    // Upstream is the distribution of the time the input iterator took to produce each value.
+    Upstream Histogram
+    // Downstream is the distribution of the time the rest of the pipeline took to process each value.
+    Downstream Histogram
+    // Runs is the number of iterations that are over, by reason.
+    Runs map[DoneReason]int64
+    // Elapsed is the total duration of the iterations that are over.
+    Elapsed time.Duration
+}
+
+// Rate returns the throughput in values per second, that is Count divided by the time spent on the values both upstream and downstream.
+// It returns 0 if no value has been yielded.
+func (s StageStats) Rate() float64 {
+    busy := s.Upstream.Sum + s.Downstream.Sum
+    if busy <= 0 {
+        return 0
+    }
+    return float64(s.Count) / busy.Seconds()
+}
+
+// MemoryRecorder is a Recorder that keeps the measurements of each name in memory, it is safe for concurrent use.
+type MemoryRecorder struct {
+    mu     sync.Mutex
+    bounds []time.Duration
+    stages map[string]*StageStats
+}
+
+// NewMemoryRecorder creates a MemoryRecorder, whose histograms use the given bucket bounds in ascending order, or DefaultHistogramBounds if none are given.
+func NewMemoryRecorder(bounds ...time.Duration) *MemoryRecorder {
+    if len(bounds) == 0 {
+        bounds = DefaultHistogramBounds
+    }
+    return &MemoryRecorder{
+        bounds: slices.Clone(bounds),
+        stages: map[string]*StageStats{},
+    }
+}
+
+func (r *MemoryRecorder) Yielded(name string, upstream, downstream time.Duration) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    s := r.stage(name)
+    s.Count++
+    s.Upstream.observe(upstream)
+    s.Downstream.observe(downstream)
+}
+
+func (r *MemoryRecorder) Done(name string, reason DoneReason, elapsed time.Duration) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    s := r.stage(name)
+    s.Runs[reason]++
+    s.Elapsed += elapsed
+}
+
+// Stats returns a copy of the stats recorded for name, the second return value is false if nothing has been recorded for it.
+func (r *MemoryRecorder) Stats(name string) (StageStats, bool) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    s, ok := r.stages[name]
+    if !ok {
+        return StageStats{}, false
+    }
+    return s.clone(), true
+}
+
+// Names returns the names that have been recorded, in ascending order.
+func (r *MemoryRecorder) Names() []string {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    names := make([]string, 0, len(r.stages))
+    for name := range r.stages {
+        names = append(names, name)
+    }
+    slices.Sort(names)
+    return names
+}
+
+// Reset discards everything that has been recorded.
+func (r *MemoryRecorder) Reset() {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.stages = map[string]*StageStats{}
+}
+
+func (r *MemoryRecorder) stage(name string) *StageStats {
+    s, ok := r.stages[name]
+    if !ok {
+        s = &StageStats{
+            Upstream:   newHistogram(r.bounds),
+            Downstream: newHistogram(r.bounds),
+            Runs:       map[DoneReason]int64{},
+        }
+        r.stages[name] = s
+    }
+    return s
+}
+
+func (s StageStats) clone() StageStats {
+    s.Upstream = s.Upstream.clone()
+    s.Downstream = s.Downstream.clone()
+    runs := make(map[DoneReason]int64, len(s.Runs))
+    for reason, n := range s.Runs {
+        runs[reason] = n
+    }
+    s.Runs = runs
+    return s
+}
@@ -0,0 +1,158 @@
+package goiter
+
+import (
+    "fmt"
+    "slices"
+    "testing"
+    "time"
+)
+
+func TestInstrument(t *testing.T) {
+    recorder := NewMemoryRecorder()
+    slowSource := Iterator[int](func(yield func(int) bool) {
+        for i := range 3 {
+            time.Sleep(2 * time.Millisecond)
+            if !yield(i) {
+                return
+            }
+        }
+    })
+    iterator := Instrument(slowSource, "source", recorder).
+        Filter(func(v int) bool { return v > 0 }).
+        Instrument("filter", recorder)
+    actual := []int{}
+    for v := range iterator {
+        time.Sleep(2 * time.Millisecond)
+        actual = append(actual, v)
+    }
+    if !slices.Equal([]int{1, 2}, actual) {
+        t.Fatal(fmt.Sprintf("expect: [1 2], actual: %v", actual))
+    }
+
+    if names := recorder.Names(); !slices.Equal([]string{"filter", "source"}, names) {
+        t.Fatal(fmt.Sprintf("expect: [filter source], actual: %v", names))
+    }
+    source, ok := recorder.Stats("source")
+    if !ok || source.Count != 3 || source.Upstream.Count != 3 || source.Downstream.Count != 3 {
+        t.Fatal(fmt.Sprintf("unexpected stats: %+v", source))
+    }
+    if source.Upstream.Min < 2*time.Millisecond {
+        t.Fatal("expect the sleep of the source to be counted upstream, actual:", source.Upstream.Min)
+    }
+    if source.Downstream.Max < 2*time.Millisecond {
+        t.Fatal("expect the sleep of the loop body to be counted downstream, actual:", source.Downstream.Max)
+    }
+    if source.Runs[DoneExhausted] != 1 || source.Elapsed < 10*time.Millisecond {
+        t.Fatal(fmt.Sprintf("unexpected runs: %v %v", source.Runs, source.Elapsed))
+    }
+    if rate := source.Rate(); rate <= 0 || rate > 1000 {
+        t.Fatal("unexpected rate:", rate)
+    }
+
+    filter, _ := recorder.Stats("filter")
+    if filter.Count != 2 || filter.Downstream.Min < 2*time.Millisecond {
+        t.Fatal(fmt.Sprintf("unexpected stats: %+v", filter))
+    }
+
+    // breaking out of the loop
+    for _ = range Instrument(Range(1, 3), "broken", recorder) {
+        break
+    }
+    broken, _ := recorder.Stats("broken")
+    if broken.Count != 1 || broken.Runs[DoneBroken] != 1 {
+        t.Fatal(fmt.Sprintf("unexpected stats: %+v", broken))
+    }
+
+    // stats are copies
+    broken.Upstream.Counts[0] = 100
+    broken.Runs[DoneBroken] = 100
+    if again, _ := recorder.Stats("broken"); again.Upstream.Counts[0] == 100 || again.Runs[DoneBroken] != 1 {
+        t.Fatal("expect the stats to be copied")
+    }
+
+    if _, ok := recorder.Stats("unknown"); ok {
+        t.Fatal("expect no stats for an unknown name")
+    }
+    recorder.Reset()
+    if names := recorder.Names(); len(names) != 0 {
+        t.Fatal("expect no names after reset, actual:", names)
+    }
+}
+
+func TestInstrument2(t *testing.T) {
+    recorder := &fakeRecorder{}
+    func() {
+        defer func() { _ = recover() }()
+        for _, _ = range Slice([]string{"a", "b"}).Instrument("slice", recorder) {
+            panic("body")
+        }
+    }()
+    // the value whose processing panics is not recorded
+    expect := []string{"done slice panicked"}
+    if !slices.Equal(expect, recorder.events) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, recorder.events))
+    }
+
+    recorder.events = nil
+    for _, _ = range Instrument2(Slice([]string{"a", "b"}), "slice", recorder) {
+    }
+    expect = []string{"yielded slice", "yielded slice", "done slice exhausted"}
+    if !slices.Equal(expect, recorder.events) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, recorder.events))
+    }
+}
+
+func TestHistogram(t *testing.T) {
+    h := newHistogram([]time.Duration{time.Millisecond, 10 * time.Millisecond})
+    if h.Quantile(0.5) != 0 || h.Mean() != 0 {
+        t.Fatal("expect zero values of an empty histogram")
+    }
+    for _, d := range []time.Duration{500 * time.Microsecond, time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 20 * time.Millisecond} {
+        h.observe(d)
+    }
+    if !slices.Equal([]int64{2, 2, 1}, h.Counts) {
+        t.Fatal(fmt.Sprintf("expect: [2 2 1], actual: %v", h.Counts))
+    }
+    if h.Count != 5 || h.Min != 500*time.Microsecond || h.Max != 20*time.Millisecond || h.Sum != 26500*time.Microsecond {
+        t.Fatal(fmt.Sprintf("unexpected histogram: %+v", h))
+    }
+    if h.Mean() != 5300*time.Microsecond {
+        t.Fatal("unexpected mean:", h.Mean())
+    }
+
+    cases := []struct {
+        q      float64
+        expect time.Duration
+    }{
+        {0, time.Millisecond},
+        {0.4, time.Millisecond},
+        {0.5, 10 * time.Millisecond},
+        {0.8, 10 * time.Millisecond},
+        {0.99, 20 * time.Millisecond},
+        {1, 20 * time.Millisecond},
+    }
+    for _, c := range cases {
+        if actual := h.Quantile(c.q); actual != c.expect {
+            t.Fatal(fmt.Sprintf("quantile %v, expect: %v, actual: %v", c.q, c.expect, actual))
+        }
+    }
+
+    // the bound is capped by the maximum
+    h2 := newHistogram([]time.Duration{time.Second})
+    h2.observe(time.Millisecond)
+    if h2.Quantile(0.5) != time.Millisecond {
+        t.Fatal("unexpected quantile:", h2.Quantile(0.5))
+    }
+}
+
+type fakeRecorder struct {
+    events []string
+}
+
+func (r *fakeRecorder) Yielded(name string, upstream, downstream time.Duration) {
+    r.events = append(r.events, "yielded "+name)
+}
+
+func (r *fakeRecorder) Done(name string, reason DoneReason, elapsed time.Duration) {
+    r.events = append(r.events, fmt.Sprintf("done %s %s", name, reason))
+}
@@ -2,6 +2,7 @@ package goiter
 
 import (
     "iter"
+    "log/slog"
 )
 
 type SeqX[T any] interface {
@@ -26,6 +27,14 @@ func (it Iterator[T]) StableOrderBy(cmp func(T, T) int) Iterator[T] {
     return StableOrderBy(it, cmp)
 }
 
+func (it Iterator[T]) TopK(k int, cmp func(T, T) int) Iterator[T] {
+    return TopK(it, k, cmp)
+}
+
+func (it Iterator[T]) BottomK(k int, cmp func(T, T) int) Iterator[T] {
+    return BottomK(it, k, cmp)
+}
+
 func (it Iterator[T]) Filter(predicate func(T) bool) Iterator[T] {
     return Filter(it, predicate)
 }
@@ -66,6 +75,10 @@ func (it Iterator[T]) Cache() Iterator[T] {
     return Cache(it)
 }
 
+func (it Iterator[T]) CacheWith(opts *CacheOptions) *Cached[T] {
+    return CacheWith(it, opts)
+}
+
 func (it Iterator[T]) Once() Iterator[T] {
     return Once(it)
 }
@@ -73,3 +86,15 @@ func (it Iterator[T]) Once() Iterator[T] {
 func (it Iterator[T]) FinishOnce() Iterator[T] {
     return FinishOnce(it)
 }
+
+func (it Iterator[T]) Tap(f func(T)) Iterator[T] {
+    return Tap(it, f)
+}
+
+func (it Iterator[T]) Trace(logger *slog.Logger, name string, format ...func(T) any) Iterator[T] {
+    return Trace(it, logger, name, format...)
+}
+
+func (it Iterator[T]) Instrument(name string, recorder Recorder) Iterator[T] {
+    return Instrument(it, name, recorder)
+}
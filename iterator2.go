@@ -2,6 +2,7 @@ package goiter
 
 import (
     "iter"
+    "log/slog"
 )
 
 type Seq2X[T1, T2 any] interface {
@@ -32,6 +33,22 @@ func (it Iterator2[T1, T2]) StableOrderBy(cmp func(*Combined[T1, T2], *Combined[
     return StableOrder2By(it, cmp)
 }
 
+func (it Iterator2[T1, T2]) OrderByTuple(cmp func(a1 T1, a2 T2, b1 T1, b2 T2) int) Iterator2[T1, T2] {
+    return Order2ByTuple(it, cmp)
+}
+
+func (it Iterator2[T1, T2]) StableOrderByTuple(cmp func(a1 T1, a2 T2, b1 T1, b2 T2) int) Iterator2[T1, T2] {
+    return StableOrder2ByTuple(it, cmp)
+}
+
+func (it Iterator2[T1, T2]) TopK(k int, cmp func(*Combined[T1, T2], *Combined[T1, T2]) int) Iterator2[T1, T2] {
+    return TopK2(it, k, cmp)
+}
+
+func (it Iterator2[T1, T2]) BottomK(k int, cmp func(*Combined[T1, T2], *Combined[T1, T2]) int) Iterator2[T1, T2] {
+    return BottomK2(it, k, cmp)
+}
+
 func (it Iterator2[T1, T2]) Filter(cmp func(T1, T2) bool) Iterator2[T1, T2] {
     return Filter2(it, cmp)
 }
@@ -72,6 +89,10 @@ func (it Iterator2[T1, T2]) Cache() Iterator2[T1, T2] {
     return Cache2(it)
 }
 
+func (it Iterator2[T1, T2]) CacheWith(opts *CacheOptions) *Cached2[T1, T2] {
+    return CacheWith2(it, opts)
+}
+
 func (it Iterator2[T1, T2]) Once() Iterator2[T1, T2] {
     return Once2(it)
 }
@@ -79,3 +100,15 @@ func (it Iterator2[T1, T2]) Once() Iterator2[T1, T2] {
 func (it Iterator2[T1, T2]) FinishOnce() Iterator2[T1, T2] {
     return FinishOnce2(it)
 }
+
+func (it Iterator2[T1, T2]) Tap(f func(T1, T2)) Iterator2[T1, T2] {
+    return Tap2(it, f)
+}
+
+func (it Iterator2[T1, T2]) Trace(logger *slog.Logger, name string, format ...func(T1, T2) any) Iterator2[T1, T2] {
+    return Trace2(it, logger, name, format...)
+}
+
+func (it Iterator2[T1, T2]) Instrument(name string, recorder Recorder) Iterator2[T1, T2] {
+    return Instrument2(it, name, recorder)
+}
@@ -0,0 +1,170 @@
+package goiter
+
+// DoneReason tells why an iteration is over, it is reported by OnDone and OnDone2.
+type DoneReason int
+
+const (
+    // DoneExhausted means the iterator has yielded all its values.
+    DoneExhausted DoneReason = iota
+    // DoneBroken means the consumer stopped early, for example, by breaking out of the loop.
+    DoneBroken
+    // DonePanicked means a panic occurred during the iteration, either in the iterator or in the loop body.
+    DonePanicked
+)
+
+func (r DoneReason) String() string {
+    switch r {
+    case DoneExhausted:
+        return "exhausted"
+    case DoneBroken:
+        return "broken"
+    case DonePanicked:
+        return "panicked"
+    }
+    return "unknown"
+}
+
+// OnDone returns an iterator that yields the same values as the input iterator, and calls onDone with the reason once each iteration is over.
+// onDone is called even if the iteration panics, and the panic keeps propagating after onDone returns.
+// For example:
+//
+//  iterator := goiter.OnDone(goiter.Items(1, 2, 3), func(reason goiter.DoneReason) {
+//      fmt.Println(reason)
+//  })
+//  for v := range iterator {   // prints "broken" after the loop
+//      if v == 2 {
+//          break
+//      }
+//  }
+//  for v := range iterator {   // prints "exhausted" after the loop
+//  }
+func OnDone[TIter SeqX[T], T any](iterator TIter, onDone func(DoneReason)) Iterator[T] {
+    return func(yield func(T) bool) {
+        broken, completed := false, false
+        defer func() {
+            switch {
+            case !completed:
+                onDone(DonePanicked)
+            case broken:
+                onDone(DoneBroken)
+            default:
+                onDone(DoneExhausted)
+            }
+        }()
+
+        // the input iterator is called directly instead of being ranged over, so that a panic raised by it after the consumer stops,
+        // such as one in its deferred cleanup, is still reported as DonePanicked.
+        iterator(func(v T) bool {
+            if !yield(v) {
+                broken = true
+                return false
+            }
+            return true
+        })
+        completed = true
+    }
+}
+
+// OnDone2 is the iter.Seq2 version of OnDone function.
+func OnDone2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter, onDone func(DoneReason)) Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        broken, completed := false, false
+        defer func() {
+            switch {
+            case !completed:
+                onDone(DonePanicked)
+            case broken:
+                onDone(DoneBroken)
+            default:
+                onDone(DoneExhausted)
+            }
+        }()
+
+        // the input iterator is called directly instead of being ranged over, so that a panic raised by it after the consumer stops,
+        // such as one in its deferred cleanup, is still reported as DonePanicked.
+        iterator(func(v1 T1, v2 T2) bool {
+            if !yield(v1, v2) {
+                broken = true
+                return false
+            }
+            return true
+        })
+        completed = true
+    }
+}
+
+// Using returns an iterator that owns a resource, such as a file or a database cursor.
+// Every time the iteration starts, acquire is called to get the resource, then the values of the iterator built by build are yielded,
+// and release is called once the iteration is over, whether it is exhausted, broken out of, or panicked.
+// Since acquiring the resource may fail, the returned iterator yields 2-tuples of (value, error),
+// if acquire returns an error, it yields the zero value along with the error, and neither build nor release is called.
+// For example:
+//
+//  lines := goiter.Using(
+//      func() (*os.File, error) { return os.Open("data.txt") },
+//      func(f *os.File) goiter.Iterator[string] { return readLines(f) },
+//      func(f *os.File) { f.Close() },
+//  )
+//  for line, err := range lines {
+//      ...
+//  }
+//
+// For an iter.Seq2 resource, use Using2.
+func Using[R, T any](
+    acquire func() (R, error),
+    build func(R) Iterator[T],
+    release func(R),
+) Iterator2[T, error] {
+    return func(yield func(T, error) bool) {
+        r, err := acquire()
+        if err != nil {
+            var zero T
+            yield(zero, err)
+            return
+        }
+        defer release(r)
+
+        for v := range build(r) {
+            if !yield(v, nil) {
+                return
+            }
+        }
+    }
+}
+
+// Using2 is the iter.Seq2 version of Using function.
+// Since the returned iterator also has to yield the errors, each 2-tuple of the iterator built by build is yielded as a *Combined,
+// and if acquire returns an error, it yields nil along with the error.
+// For example:
+//
+//  rows := goiter.Using2(
+//      func() (*sql.Rows, error) { return db.Query("SELECT id, name FROM users") },
+//      func(r *sql.Rows) goiter.Iterator2[int64, string] { return scanUsers(r) },
+//      func(r *sql.Rows) { r.Close() },
+//  )
+//  for user, err := range rows {
+//      if err != nil {
+//          return err
+//      }
+//      fmt.Println(user.V1, user.V2)
+//  }
+func Using2[R, T1, T2 any](
+    acquire func() (R, error),
+    build func(R) Iterator2[T1, T2],
+    release func(R),
+) Iterator2[*Combined[T1, T2], error] {
+    return func(yield func(*Combined[T1, T2], error) bool) {
+        r, err := acquire()
+        if err != nil {
+            yield(nil, err)
+            return
+        }
+        defer release(r)
+
+        for v1, v2 := range build(r) {
+            if !yield(&Combined[T1, T2]{V1: v1, V2: v2}, nil) {
+                return
+            }
+        }
+    }
+}
@@ -0,0 +1,277 @@
+package goiter
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "testing"
+)
+
+func TestOnDone(t *testing.T) {
+    reasons := []DoneReason{}
+    iterator := OnDone(Items(1, 2, 3), func(reason DoneReason) {
+        reasons = append(reasons, reason)
+    })
+
+    for v := range iterator {
+        if v == 2 {
+            break
+        }
+    }
+    actual := []int{}
+    for v := range iterator {
+        actual = append(actual, v)
+    }
+    expect := []int{1, 2, 3}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    func() {
+        defer func() {
+            if recover() != "boom" {
+                t.Fatal("expect the panic to keep propagating")
+            }
+        }()
+        for _ = range iterator {
+            panic("boom")
+        }
+    }()
+
+    // the input iterator panics in its deferred cleanup after the consumer breaks
+    cleanupPanicking := OnDone(Iterator[int](func(yield func(int) bool) {
+        defer func() {
+            panic("cleanup failed")
+        }()
+        for _, v := range []int{1, 2, 3} {
+            if !yield(v) {
+                return
+            }
+        }
+    }), func(reason DoneReason) {
+        reasons = append(reasons, reason)
+    })
+    func() {
+        defer func() {
+            if recover() != "cleanup failed" {
+                t.Fatal("expect the panic to keep propagating")
+            }
+        }()
+        for _ = range cleanupPanicking {
+            break
+        }
+    }()
+
+    expectReasons := []DoneReason{DoneBroken, DoneExhausted, DonePanicked, DonePanicked}
+    if !slices.Equal(expectReasons, reasons) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectReasons, reasons))
+    }
+}
+
+func TestOnDone2(t *testing.T) {
+    reasons := []DoneReason{}
+    iterator := OnDone2(Slice([]string{"a", "b"}), func(reason DoneReason) {
+        reasons = append(reasons, reason)
+    })
+
+    for _, _ = range iterator {
+        break
+    }
+    if c := Count2(iterator); c != 2 {
+        t.Fatal(fmt.Sprintf("expect: 2, actual: %d", c))
+    }
+
+    panicking := OnDone2(Iterator2[int, int](func(yield func(int, int) bool) {
+        panic("boom")
+    }), func(reason DoneReason) {
+        reasons = append(reasons, reason)
+    })
+    func() {
+        defer func() {
+            _ = recover()
+        }()
+        Count2(panicking)
+    }()
+
+    cleanupPanicking := OnDone2(Iterator2[int, int](func(yield func(int, int) bool) {
+        defer func() {
+            panic("cleanup failed")
+        }()
+        yield(1, 1)
+    }), func(reason DoneReason) {
+        reasons = append(reasons, reason)
+    })
+    func() {
+        defer func() {
+            _ = recover()
+        }()
+        for _, _ = range cleanupPanicking {
+            break
+        }
+    }()
+
+    expectReasons := []DoneReason{DoneBroken, DoneExhausted, DonePanicked, DonePanicked}
+    if !slices.Equal(expectReasons, reasons) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectReasons, reasons))
+    }
+    if fmt.Sprint(expectReasons) != "[broken exhausted panicked panicked]" || DoneReason(-1).String() != "unknown" {
+        t.Fatal("unexpected string form:", expectReasons)
+    }
+}
+
+func TestUsing(t *testing.T) {
+    type resource struct {
+        values   []int
+        released bool
+    }
+    var last *resource
+    acquire := func() (*resource, error) {
+        last = &resource{values: []int{1, 2, 3}}
+        return last, nil
+    }
+    build := func(r *resource) Iterator[int] {
+        return SliceElems(r.values)
+    }
+    release := func(r *resource) {
+        r.released = true
+    }
+    iterator := Using(acquire, build, release)
+
+    // case 1: exhausted
+    actual := []int{}
+    for v, err := range iterator {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, v)
+    }
+    expect := []int{1, 2, 3}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    if !last.released {
+        t.Fatal("expect the resource to be released")
+    }
+
+    // case 2: broken
+    for _, _ = range iterator {
+        if last.released {
+            t.Fatal("expect the resource to be held during the iteration")
+        }
+        break
+    }
+    if !last.released {
+        t.Fatal("expect the resource to be released")
+    }
+
+    // case 3: panicked
+    func() {
+        defer func() {
+            _ = recover()
+        }()
+        for _, _ = range iterator {
+            panic("boom")
+        }
+    }()
+    if !last.released {
+        t.Fatal("expect the resource to be released")
+    }
+
+    // case 4: failed to acquire
+    errAcquire := errors.New("acquire failed")
+    built := false
+    count := 0
+    for _, err := range Using(func() (*resource, error) {
+        return nil, errAcquire
+    }, func(r *resource) Iterator[int] {
+        built = true
+        return build(r)
+    }, release) {
+        count++
+        if !errors.Is(err, errAcquire) {
+            t.Fatal("expect error:", errAcquire, "actual:", err)
+        }
+    }
+    if count != 1 || built {
+        t.Fatal(fmt.Sprintf("expect: 1 false, actual: %d %v", count, built))
+    }
+}
+
+func TestUsing2(t *testing.T) {
+    type resource struct {
+        values   map[string]int
+        released bool
+    }
+    var last *resource
+    acquire := func() (*resource, error) {
+        last = &resource{values: map[string]int{"a": 1, "b": 2}}
+        return last, nil
+    }
+    build := func(r *resource) Iterator2[string, int] {
+        return Map(r.values)
+    }
+    release := func(r *resource) {
+        r.released = true
+    }
+    iterator := Using2(acquire, build, release)
+
+    // case 1: exhausted
+    actual := []string{}
+    for v, err := range iterator {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, fmt.Sprintf("%s:%d", v.V1, v.V2))
+    }
+    slices.Sort(actual)
+    expect := []string{"a:1", "b:2"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    if !last.released {
+        t.Fatal("expect the resource to be released")
+    }
+
+    // case 2: broken
+    for _, _ = range iterator {
+        if last.released {
+            t.Fatal("expect the resource to be held during the iteration")
+        }
+        break
+    }
+    if !last.released {
+        t.Fatal("expect the resource to be released")
+    }
+
+    // case 3: panicked
+    func() {
+        defer func() {
+            _ = recover()
+        }()
+        for _, _ = range iterator {
+            panic("boom")
+        }
+    }()
+    if !last.released {
+        t.Fatal("expect the resource to be released")
+    }
+
+    // case 4: failed to acquire
+    errAcquire := errors.New("acquire failed")
+    built := false
+    count := 0
+    for v, err := range Using2(func() (*resource, error) {
+        return nil, errAcquire
+    }, func(r *resource) Iterator2[string, int] {
+        built = true
+        return build(r)
+    }, release) {
+        count++
+        if v != nil || !errors.Is(err, errAcquire) {
+            t.Fatal("expect error:", errAcquire, "actual:", v, err)
+        }
+    }
+    if count != 1 || built {
+        t.Fatal(fmt.Sprintf("expect: 1 false, actual: %d %v", count, built))
+    }
+}
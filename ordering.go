@@ -2,7 +2,10 @@ package goiter
 
 import (
     "cmp"
+    "math/rand/v2"
     "slices"
+    "sort"
+    "sync"
 )
 
 // Order sorts the elements of the input iterator and returns a new iterator whose elements are arranged in ascending or descending order.
@@ -45,19 +48,18 @@ func Order2V1[TIter Seq2X[T1, T2], T1 cmp.Ordered, T2 any](
     iterator TIter,
     desc ...bool,
 ) Iterator2[T1, T2] {
-    var cmpFunc func(a *Combined[T1, T2], b *Combined[T1, T2]) int
-
+    var cmpFunc func(a1 T1, a2 T2, b1 T1, b2 T2) int
     if len(desc) > 0 && desc[0] {
-        cmpFunc = func(a *Combined[T1, T2], b *Combined[T1, T2]) int {
-            return cmp.Compare(b.V1, a.V1)
+        cmpFunc = func(a1 T1, _ T2, b1 T1, _ T2) int {
+            return cmp.Compare(b1, a1)
         }
     } else {
-        cmpFunc = func(a *Combined[T1, T2], b *Combined[T1, T2]) int {
-            return cmp.Compare(a.V1, b.V1)
+        cmpFunc = func(a1 T1, _ T2, b1 T1, _ T2) int {
+            return cmp.Compare(a1, b1)
         }
     }
 
-    return doOrderBy2(iterator, cmpFunc, slices.SortFunc[[]*Combined[T1, T2], *Combined[T1, T2]])
+    return doOrderBy2(iterator, sortTuples(cmpFunc, false))
 }
 
 // Order2V2 is like Order2V1, but it sorts by the second element of the 2-tuples.
@@ -66,18 +68,18 @@ func Order2V2[TIter Seq2X[T1, T2], T1 any, T2 cmp.Ordered](
     iterator TIter,
     desc ...bool,
 ) Iterator2[T1, T2] {
-    var cmpFunc func(a *Combined[T1, T2], b *Combined[T1, T2]) int
+    var cmpFunc func(a1 T1, a2 T2, b1 T1, b2 T2) int
     if len(desc) > 0 && desc[0] {
-        cmpFunc = func(a *Combined[T1, T2], b *Combined[T1, T2]) int {
-            return cmp.Compare(b.V2, a.V2)
+        cmpFunc = func(_ T1, a2 T2, _ T1, b2 T2) int {
+            return cmp.Compare(b2, a2)
         }
     } else {
-        cmpFunc = func(a *Combined[T1, T2], b *Combined[T1, T2]) int {
-            return cmp.Compare(a.V2, b.V2)
+        cmpFunc = func(_ T1, a2 T2, _ T1, b2 T2) int {
+            return cmp.Compare(a2, b2)
         }
     }
 
-    return doOrderBy2(iterator, cmpFunc, slices.SortFunc[[]*Combined[T1, T2], *Combined[T1, T2]])
+    return doOrderBy2(iterator, sortTuples(cmpFunc, false))
 }
 
 // OrderBy accepts a comparison function and returns a new iterator that yields elements sorted by the comparison function.
@@ -95,7 +97,27 @@ func Order2By[TIter Seq2X[T1, T2], T1, T2 any](
     iterator TIter,
     cmp func(*Combined[T1, T2], *Combined[T1, T2]) int,
 ) Iterator2[T1, T2] {
-    return doOrderBy2(iterator, cmp, slices.SortFunc[[]*Combined[T1, T2], *Combined[T1, T2]])
+    return doOrderBy2(iterator, sortCombined(cmp, false))
+}
+
+// Order2ByTuple is like Order2By, but the comparison function takes the elements of the two 2-tuples to compare as four arguments,
+// so no Combined values need to be passed around.
+// For example:
+//
+//  // sort by score in descending order, then by name
+//  iterator := goiter.Order2ByTuple(goiter.Map(scores), func(name1 string, score1 int, name2 string, score2 int) int {
+//      if c := cmp.Compare(score2, score1); c != 0 {
+//          return c
+//      }
+//      return cmp.Compare(name1, name2)
+//  })
+//
+// Note: if this function is used on iterators that has massive amount of data, it might consume a lot of memory.
+func Order2ByTuple[TIter Seq2X[T1, T2], T1, T2 any](
+    iterator TIter,
+    cmp func(a1 T1, a2 T2, b1 T1, b2 T2) int,
+) Iterator2[T1, T2] {
+    return doOrderBy2(iterator, sortTuples(cmp, false))
 }
 
 // StableOrderBy is like OrderBy, but it uses a stable sort algorithm.
@@ -113,7 +135,59 @@ func StableOrder2By[TIter Seq2X[T1, T2], T1, T2 any](
     iterator TIter,
     cmp func(*Combined[T1, T2], *Combined[T1, T2]) int,
 ) Iterator2[T1, T2] {
-    return doOrderBy2(iterator, cmp, slices.SortStableFunc[[]*Combined[T1, T2], *Combined[T1, T2]])
+    return doOrderBy2(iterator, sortCombined(cmp, true))
+}
+
+// StableOrder2ByTuple is like Order2ByTuple, but it uses a stable sort algorithm.
+// Note: if this function is used on iterators that has massive amount of data, it might consume a lot of memory.
+func StableOrder2ByTuple[TIter Seq2X[T1, T2], T1, T2 any](
+    iterator TIter,
+    cmp func(a1 T1, a2 T2, b1 T1, b2 T2) int,
+) Iterator2[T1, T2] {
+    return doOrderBy2(iterator, sortTuples(cmp, true))
+}
+
+// Shuffle returns an iterator that yields the elements of the input iterator in random order.
+// The randomness comes from rng, if rng is nil, a randomly seeded one is used.
+// Every iteration draws a seed from rng when it starts and uses its own generator seeded with it, so ranging over the returned iterator again
+// yields another order, and it is safe to range over it from multiple goroutines even though rand.Rand is not.
+// With a fixed seed, the orders yielded by the successive iterations are reproducible, as long as nothing else draws from rng in between.
+// It buffers all the elements first, and then performs the Fisher–Yates shuffle step by step while yielding,
+// so breaking out of the loop early saves the cost of shuffling the rest.
+//
+// Note: if this function is used on iterators that has massive amount of data, it might consume a lot of memory.
+func Shuffle[TIter SeqX[T], T any](
+    iterator TIter,
+    rng *rand.Rand,
+) Iterator[T] {
+    return func(yield func(T) bool) {
+        r := iterationRand(rng)
+        s := make([]T, 0)
+        for each := range iterator {
+            s = append(s, each)
+        }
+
+        for i := len(s) - 1; i >= 0; i-- {
+            j := r.IntN(i + 1)
+            s[i], s[j] = s[j], s[i]
+            if !yield(s[i]) {
+                return
+            }
+        }
+    }
+}
+
+// rngLock guards the rand.Rand passed by callers, as they are shared by all the iterations of the iterators built with them.
+var rngLock sync.Mutex
+
+// iterationRand returns a generator for a single iteration, seeded from rng, or randomly seeded if rng is nil.
+func iterationRand(rng *rand.Rand) *rand.Rand {
+    if rng == nil {
+        return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+    }
+    rngLock.Lock()
+    defer rngLock.Unlock()
+    return rand.New(rand.NewPCG(rng.Uint64(), rng.Uint64()))
 }
 
 type tSortFunc[S ~[]T, T any] func(x S, cmp func(a, b T) int)
@@ -138,25 +212,230 @@ func doOrderBy[TIter SeqX[T], T any](
     }
 }
 
+// doOrderBy2 buffers the 2-tuples as Combined values rather than pointers, so it does not allocate for each element.
 func doOrderBy2[TIter Seq2X[T1, T2], T1, T2 any](
     iterator TIter,
-    cmp func(*Combined[T1, T2], *Combined[T1, T2]) int,
-    sortFunc tSortFunc[[]*Combined[T1, T2], *Combined[T1, T2]],
+    sortFunc func([]Combined[T1, T2]),
 ) Iterator2[T1, T2] {
     return func(yield func(T1, T2) bool) {
-        tuples := make([]*Combined[T1, T2], 0)
+        tuples := make([]Combined[T1, T2], 0)
         for v1, v2 := range iterator {
-            tuples = append(tuples, &Combined[T1, T2]{
+            tuples = append(tuples, Combined[T1, T2]{
                 V1: v1,
                 V2: v2,
             })
         }
 
-        sortFunc(tuples, cmp)
-        for _, each := range tuples {
+        sortFunc(tuples)
+        for i := range tuples {
+            if !yield(tuples[i].V1, tuples[i].V2) {
+                return
+            }
+        }
+    }
+}
+
+func sortTuples[T1, T2 any](cmp func(a1 T1, a2 T2, b1 T1, b2 T2) int, stable bool) func([]Combined[T1, T2]) {
+    cmpFunc := func(a, b Combined[T1, T2]) int {
+        return cmp(a.V1, a.V2, b.V1, b.V2)
+    }
+    return func(tuples []Combined[T1, T2]) {
+        if stable {
+            slices.SortStableFunc(tuples, cmpFunc)
+        } else {
+            slices.SortFunc(tuples, cmpFunc)
+        }
+    }
+}
+
+// sortCombined sorts by a comparison function of Combined pointers, the pointers point into the slice being sorted,
+// so the values are neither copied nor moved to the heap in order to be compared.
+func sortCombined[T1, T2 any](cmp func(*Combined[T1, T2], *Combined[T1, T2]) int, stable bool) func([]Combined[T1, T2]) {
+    return func(tuples []Combined[T1, T2]) {
+        s := &combinedSorter[T1, T2]{tuples: tuples, cmp: cmp}
+        if stable {
+            sort.Stable(s)
+        } else {
+            sort.Sort(s)
+        }
+    }
+}
+
+type combinedSorter[T1, T2 any] struct {
+    tuples []Combined[T1, T2]
+    cmp    func(*Combined[T1, T2], *Combined[T1, T2]) int
+}
+
+func (s *combinedSorter[T1, T2]) Len() int {
+    return len(s.tuples)
+}
+
+func (s *combinedSorter[T1, T2]) Less(i, j int) bool {
+    return s.cmp(&s.tuples[i], &s.tuples[j]) < 0
+}
+
+func (s *combinedSorter[T1, T2]) Swap(i, j int) {
+    s.tuples[i], s.tuples[j] = s.tuples[j], s.tuples[i]
+}
+
+// TopK returns an iterator that yields the k greatest elements of the input iterator in descending order according to the comparison function.
+// Unlike OrderBy, it only keeps k elements in memory at any time, so it is suitable for selecting a few elements from a huge amount of data.
+// For example:
+//
+//	if the input iterator yields 5 1 4 2 3, TopK(iterator, 2, cmp.Compare[int]) will yield 5 4.
+//
+// If k is less than or equal to 0, it yields nothing.
+func TopK[TIter SeqX[T], T any](
+    iterator TIter,
+    k int,
+    cmp func(T, T) int,
+) Iterator[T] {
+    return doSelectK(iterator, k, cmp)
+}
+
+// BottomK is like TopK, but it yields the k least elements in ascending order.
+func BottomK[TIter SeqX[T], T any](
+    iterator TIter,
+    k int,
+    cmp func(T, T) int,
+) Iterator[T] {
+    return doSelectK(iterator, k, func(a, b T) int {
+        return cmp(b, a)
+    })
+}
+
+// TopK2 is the iter.Seq2 version of TopK, it accepts the same comparison function as Order2By.
+func TopK2[TIter Seq2X[T1, T2], T1, T2 any](
+    iterator TIter,
+    k int,
+    cmp func(*Combined[T1, T2], *Combined[T1, T2]) int,
+) Iterator2[T1, T2] {
+    return doSelectK2(iterator, k, cmp)
+}
+
+// BottomK2 is the iter.Seq2 version of BottomK, it accepts the same comparison function as Order2By.
+func BottomK2[TIter Seq2X[T1, T2], T1, T2 any](
+    iterator TIter,
+    k int,
+    cmp func(*Combined[T1, T2], *Combined[T1, T2]) int,
+) Iterator2[T1, T2] {
+    return doSelectK2(iterator, k, func(a, b *Combined[T1, T2]) int {
+        return cmp(b, a)
+    })
+}
+
+// doSelectK keeps the k greatest elements in a min-heap whose root is the least one among them,
+// so every incoming element only needs to be compared with the root to decide whether it should be kept.
+func doSelectK[TIter SeqX[T], T any](
+    iterator TIter,
+    k int,
+    cmp func(T, T) int,
+) Iterator[T] {
+    if k <= 0 {
+        return Empty[T]()
+    }
+
+    return func(yield func(T) bool) {
+        h := &kHeap[T]{cmp: cmp}
+        for each := range iterator {
+            h.offer(each, k)
+        }
+
+        slices.SortFunc(h.items, func(a, b T) int {
+            return cmp(b, a)
+        })
+        for _, each := range h.items {
+            if !yield(each) {
+                return
+            }
+        }
+    }
+}
+
+func doSelectK2[TIter Seq2X[T1, T2], T1, T2 any](
+    iterator TIter,
+    k int,
+    cmp func(*Combined[T1, T2], *Combined[T1, T2]) int,
+) Iterator2[T1, T2] {
+    if k <= 0 {
+        return Empty2[T1, T2]()
+    }
+
+    return func(yield func(T1, T2) bool) {
+        h := &kHeap[*Combined[T1, T2]]{cmp: cmp}
+        for v1, v2 := range iterator {
+            h.offer(&Combined[T1, T2]{V1: v1, V2: v2}, k)
+        }
+
+        slices.SortFunc(h.items, func(a, b *Combined[T1, T2]) int {
+            return cmp(b, a)
+        })
+        for _, each := range h.items {
             if !yield(each.V1, each.V2) {
                 return
             }
         }
     }
 }
+
+type kHeap[T any] struct {
+    items []T
+    cmp   func(T, T) int
+}
+
+func (h *kHeap[T]) offer(v T, k int) {
+    if len(h.items) < k {
+        h.push(v)
+        return
+    }
+    if h.cmp(v, h.items[0]) > 0 {
+        h.items[0] = v
+        h.down(0)
+    }
+}
+
+func (h *kHeap[T]) up(i int) {
+    for i > 0 {
+        parent := (i - 1) / 2
+        if h.cmp(h.items[i], h.items[parent]) >= 0 {
+            return
+        }
+        h.items[i], h.items[parent] = h.items[parent], h.items[i]
+        i = parent
+    }
+}
+
+func (h *kHeap[T]) down(i int) {
+    n := len(h.items)
+    for {
+        least := i
+        left, right := 2*i+1, 2*i+2
+        if left < n && h.cmp(h.items[left], h.items[least]) < 0 {
+            least = left
+        }
+        if right < n && h.cmp(h.items[right], h.items[least]) < 0 {
+            least = right
+        }
+        if least == i {
+            return
+        }
+        h.items[i], h.items[least] = h.items[least], h.items[i]
+        i = least
+    }
+}
+
+func (h *kHeap[T]) push(v T) {
+    h.items = append(h.items, v)
+    h.up(len(h.items) - 1)
+}
+
+func (h *kHeap[T]) pop() T {
+    last := len(h.items) - 1
+    root := h.items[0]
+    h.items[0] = h.items[last]
+    h.items = h.items[:last]
+    if last > 0 {
+        h.down(0)
+    }
+    return root
+}
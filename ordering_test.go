@@ -2,7 +2,10 @@ package goiter
 
 import (
     "cmp"
+    "fmt"
+    "math/rand/v2"
     "slices"
+    "sync"
     "testing"
 )
 
@@ -213,3 +216,215 @@ func TestStableOrderBy2(t *testing.T) {
         t.Fatal("expect:", expect, "actual:", actual)
     }
 }
+
+func TestOrder2ByTuple(t *testing.T) {
+    input := map[string]int{
+        "bob":   20,
+        "eve":   30,
+        "alice": 20,
+    }
+    actual := []string{}
+    iterator := Map(input).OrderByTuple(func(name1 string, age1 int, name2 string, age2 int) int {
+        if c := cmp.Compare(age2, age1); c != 0 {
+            return c
+        }
+        return cmp.Compare(name1, name2)
+    })
+    for v1, v2 := range iterator {
+        actual = append(actual, fmt.Sprintf("%s:%d", v1, v2))
+    }
+    expect := []string{"eve:30", "alice:20", "bob:20"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    // break
+    actual = []string{}
+    for v1, _ := range Order2ByTuple(Map(input), func(a1 string, _ int, b1 string, _ int) int { return cmp.Compare(a1, b1) }) {
+        actual = append(actual, v1)
+        break
+    }
+    if !slices.Equal([]string{"alice"}, actual) {
+        t.Fatal("expect: [alice], actual:", actual)
+    }
+}
+
+func TestStableOrder2ByTuple(t *testing.T) {
+    input := make([]int, 100)
+    for i := range input {
+        input[i] = i % 7
+    }
+    byValue := func(_ int, a int, _ int, b int) int { return cmp.Compare(a, b) }
+    byPointer := func(a, b *Combined[int, int]) int { return cmp.Compare(a.V2, b.V2) }
+
+    for _, iterator := range []Iterator2[int, int]{
+        Slice(input).StableOrderByTuple(byValue),
+        StableOrder2By(Slice(input), byPointer),
+    } {
+        lastIdx, lastValue := -1, -1
+        count := 0
+        for idx, v := range iterator {
+            if v < lastValue || (v == lastValue && idx < lastIdx) {
+                t.Fatal(fmt.Sprintf("unstable order: %d:%d after %d:%d", idx, v, lastIdx, lastValue))
+            }
+            lastIdx, lastValue = idx, v
+            count++
+        }
+        if count != len(input) {
+            t.Fatal(fmt.Sprintf("expect: %d, actual: %d", len(input), count))
+        }
+    }
+}
+
+func TestTopK(t *testing.T) {
+    input := []int{5, 1, 9, 4, 7, 2, 8, 3, 6}
+
+    actual := []int{}
+    for v := range TopK(SliceElems(input), 3, cmp.Compare[int]) {
+        actual = append(actual, v)
+    }
+    expect := []int{9, 8, 7}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    actual = []int{}
+    for v := range SliceElems(input).TopK(20, cmp.Compare[int]) {
+        actual = append(actual, v)
+    }
+    expect = []int{9, 8, 7, 6, 5, 4, 3, 2, 1}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    if c := Count(TopK(SliceElems(input), 0, cmp.Compare[int])); c != 0 {
+        t.Fatal("expect:", 0, "actual:", c)
+    }
+
+    // won't panic
+    for _ = range TopK(SliceElems(input), 3, cmp.Compare[int]) {
+        break
+    }
+}
+
+func TestBottomK(t *testing.T) {
+    input := []int{5, 1, 9, 4, 7, 2, 8, 3, 6}
+
+    actual := []int{}
+    for v := range SliceElems(input).BottomK(4, cmp.Compare[int]) {
+        actual = append(actual, v)
+    }
+    expect := []int{1, 2, 3, 4}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    actual = []int{}
+    for v := range BottomK(Range(1000000, 1), 2, cmp.Compare[int]) {
+        actual = append(actual, v)
+    }
+    expect = []int{1, 2}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+}
+
+func TestTopK2(t *testing.T) {
+    input := map[string]int{
+        "bob":   20,
+        "eve":   30,
+        "alice": 25,
+        "john":  18,
+    }
+    byAge := func(a, b *Combined[string, int]) int { return cmp.Compare(a.V2, b.V2) }
+
+    actual := []string{}
+    for name := range TopK2(Map(input), 2, byAge) {
+        actual = append(actual, name)
+    }
+    expect := []string{"eve", "alice"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    actual = []string{}
+    for name := range Map(input).BottomK(2, byAge) {
+        actual = append(actual, name)
+    }
+    expect = []string{"john", "bob"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    actual = []string{}
+    for name := range Map(input).TopK(1, byAge) {
+        actual = append(actual, name)
+    }
+    expect = []string{"eve"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal("expect:", expect, "actual:", actual)
+    }
+
+    if c := Count2(BottomK2(Map(input), -1, byAge)); c != 0 {
+        t.Fatal("expect:", 0, "actual:", c)
+    }
+    for _, _ = range BottomK2(Map(input), 3, byAge) {
+        break
+    }
+}
+
+func TestShuffle(t *testing.T) {
+    input := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+    actual1 := []int{}
+    for v := range Shuffle(SliceElems(input), rand.New(rand.NewPCG(1, 2))) {
+        actual1 = append(actual1, v)
+    }
+    actual2 := []int{}
+    for v := range Shuffle(SliceElems(input), rand.New(rand.NewPCG(1, 2))) {
+        actual2 = append(actual2, v)
+    }
+    if !slices.Equal(actual1, actual2) {
+        t.Fatal("expect the same seed to produce the same result:", actual1, actual2)
+    }
+    if slices.Equal(input, actual1) {
+        t.Fatal("expect the elements to be shuffled:", actual1)
+    }
+    sorted := slices.Clone(actual1)
+    slices.Sort(sorted)
+    if !slices.Equal(input, sorted) {
+        t.Fatal("expect:", input, "actual:", sorted)
+    }
+
+    if c := Count(Shuffle(SliceElems(input), nil)); c != len(input) {
+        t.Fatal("expect:", len(input), "actual:", c)
+    }
+
+    // each iteration draws its own seed, so the successive iterations are reproducible as a whole
+    shuffled1, shuffled2 := Shuffle(SliceElems(input), rand.New(rand.NewPCG(3, 4))), Shuffle(SliceElems(input), rand.New(rand.NewPCG(3, 4)))
+    for range 3 {
+        actual1, actual2 := slices.Collect(shuffled1.Seq()), slices.Collect(shuffled2.Seq())
+        if !slices.Equal(actual1, actual2) {
+            t.Fatal("expect the same seed to produce the same result:", actual1, actual2)
+        }
+    }
+
+    // ranging over the same iterator concurrently
+    shuffled := Shuffle(SliceElems(input), rand.New(rand.NewPCG(1, 2)))
+    wg := sync.WaitGroup{}
+    for range 4 {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if c := Count(shuffled); c != len(input) {
+                t.Error("expect:", len(input), "actual:", c)
+            }
+        }()
+    }
+    wg.Wait()
+
+    // won't panic
+    for _ = range Shuffle(SliceElems(input), nil) {
+        break
+    }
+}
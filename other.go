@@ -1,103 +1,276 @@
 package goiter
 
 import (
-    "iter"
-    "sync/atomic"
+    "sync"
+    "time"
 )
 
 // Cache returns an iterator that caches the values of the input iterator.
+// The values are cached when the first iteration over the returned iterator runs to the end, the following iterations yield the cached values.
+// It is the same as CacheWith(it, nil).Iter().
 func Cache[TIter SeqX[T], T any](it TIter) Iterator[T] {
-    var cached []T
-    var cacheFlag int32
+    return CacheWith(it, nil).Iter()
+}
 
-    var dynIter iter.Seq[T]
-    cachedIter := func(yield func(T) bool) {
-        for _, v := range cached {
-            if !yield(v) {
-                return
+// Cache2 is iter.Seq2 version of Cache.
+func Cache2[TIter Seq2X[T1, T2], T1 any, T2 any](it TIter) Iterator2[T1, T2] {
+    return CacheWith2(it, nil).Iter()
+}
+
+// CacheOptions configures CacheWith and CacheWith2.
+type CacheOptions struct {
+    // MaxElements is the maximum number of elements to be cached, 0 means no limit.
+    // If the input iterator yields more elements than that, nothing is cached,
+    // and every iteration iterates over the input iterator again until the cache is invalidated.
+    MaxElements int
+    // TTL is how long the values are kept once they have been fully cached, they never expire if it is not positive.
+    // The first iteration after the expiry iterates over the input iterator again and refills the cache.
+    TTL time.Duration
+    // ReplayPartial makes the iterations that start while the cache is being filled replay the values cached so far,
+    // and then wait for the filling iteration to produce the rest, instead of iterating over the input iterator on their own.
+    // If the filling iteration stops before the end, they iterate over the input iterator on their own, skipping the values they have already yielded.
+    // Note: do not iterate over a Cached iterator inside the loop that is filling it with this option on, the inner loop would wait for the outer one forever.
+    ReplayPartial bool
+}
+
+// Cached is an iterator that caches the values of its input iterator, it is created by CacheWith.
+type Cached[T any] struct {
+    c *cache[T]
+}
+
+// CacheWith is like Cache, but the cache is configured by opts, and it can be invalidated through the returned Cached.
+// If opts is nil, the default options are used, which cache all values forever.
+// The values are only cached when an iteration runs to the end, and an iteration that starts while another one is filling the cache
+// iterates over the input iterator on its own, unless opts.ReplayPartial is true.
+// Iterating over the cached iterator from multiple goroutines is safe, the values written by the filling iteration happen before they are read by the others.
+// For example:
+//
+//  users := goiter.CacheWith(loadUsers(), &goiter.CacheOptions{TTL: time.Minute, ReplayPartial: true})
+//  for user := range users.Iter() {
+//      ...
+//  }
+//  users.Invalidate()  // the next iteration loads the users again
+func CacheWith[TIter SeqX[T], T any](it TIter, opts *CacheOptions) *Cached[T] {
+    return &Cached[T]{
+        c: newCache[T](func(yield func(T) bool) {
+            for v := range it {
+                if !yield(v) {
+                    return
+                }
             }
-        }
+        }, opts),
     }
+}
 
-    originalIter := func(yield func(T) bool) {
-        cTemp := make([]T, 0)
-        next, stop := iter.Pull(iter.Seq[T](it))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                break
-            }
-            if !yield(v) {
-                return
+// Iter returns the cached iterator, all iterators returned by Iter share the same cache.
+func (c *Cached[T]) Iter() Iterator[T] {
+    return c.c.iterate
+}
+
+// Invalidate discards the cached values, the next iteration iterates over the input iterator again.
+// An iteration that is filling the cache keeps going, but what it yields is no longer cached.
+func (c *Cached[T]) Invalidate() {
+    c.c.invalidate()
+}
+
+// Cached2 is the iter.Seq2 version of Cached, it is created by CacheWith2.
+type Cached2[T1, T2 any] struct {
+    c *cache[Combined[T1, T2]]
+}
+
+// CacheWith2 is iter.Seq2 version of CacheWith.
+func CacheWith2[TIter Seq2X[T1, T2], T1 any, T2 any](it TIter, opts *CacheOptions) *Cached2[T1, T2] {
+    return &Cached2[T1, T2]{
+        c: newCache[Combined[T1, T2]](func(yield func(Combined[T1, T2]) bool) {
+            for v1, v2 := range it {
+                if !yield(Combined[T1, T2]{V1: v1, V2: v2}) {
+                    return
+                }
             }
-            cTemp = append(cTemp, v)
-        }
-        if atomic.CompareAndSwapInt32(&cacheFlag, 0, 1) {
-            cached = cTemp
-            dynIter = cachedIter
-        }
+        }, opts),
     }
-    dynIter = originalIter
-    sIter := SeqSource(func() iter.Seq[T] {
-        return dynIter
-    })
+}
+
+// Iter returns the cached iterator, all iterators returned by Iter share the same cache.
+func (c *Cached2[T1, T2]) Iter() Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        c.c.iterate(func(v Combined[T1, T2]) bool {
+            return yield(v.V1, v.V2)
+        })
+    }
+}
+
+// Invalidate discards the cached values, the next iteration iterates over the input iterator again.
+// An iteration that is filling the cache keeps going, but what it yields is no longer cached.
+func (c *Cached2[T1, T2]) Invalidate() {
+    c.c.invalidate()
+}
+
+// cache is shared by Cached and Cached2, all fields but source and opts are guarded by mu.
+type cache[T any] struct {
+    source func(yield func(T) bool)
+    opts   CacheOptions
+
+    mu   sync.Mutex
+    cond *sync.Cond
+    // gen is increased every time the cache is reset or a filling iteration stops before the end,
+    // an iteration only fills or replays the cache of the generation it started in.
+    gen    uint64
+    values []T
+    // filling is true while an iteration is filling values.
+    filling bool
+    // complete is true once values holds all values of the input iterator, they are never modified afterwards.
+    complete  bool
+    expiresAt time.Time
+    // oversized is set once the input iterator has yielded more than opts.MaxElements values.
+    oversized bool
+}
 
-    return func(yield func(T) bool) {
-        for v := range sIter {
+func newCache[T any](source func(yield func(T) bool), opts *CacheOptions) *cache[T] {
+    c := &cache[T]{source: source}
+    if opts != nil {
+        c.opts = *opts
+    }
+    c.cond = sync.NewCond(&c.mu)
+    return c
+}
+
+func (c *cache[T]) iterate(yield func(T) bool) {
+    c.mu.Lock()
+    if c.complete && c.opts.TTL > 0 && !time.Now().Before(c.expiresAt) {
+        c.reset()
+    }
+
+    switch {
+    case c.complete:
+        values := c.values
+        c.mu.Unlock()
+        for _, v := range values {
             if !yield(v) {
                 return
             }
         }
+    case c.oversized:
+        c.mu.Unlock()
+        c.source(yield)
+    case c.filling && c.opts.ReplayPartial:
+        c.replay(yield)
+    case c.filling:
+        c.mu.Unlock()
+        c.source(yield)
+    default:
+        c.fill(yield)
     }
 }
 
-// Cache2 is iter.Seq2 version of Cache.
-func Cache2[TIter Seq2X[T1, T2], T1 any, T2 any](it TIter) Iterator2[T1, T2] {
-    var cached []*Combined[T1, T2]
-    var cacheFlag int32
+// fill is called with mu held, it iterates over the input iterator and caches the values.
+// A value is cached before it is yielded, so the replaying iterations do not have to wait for the loop body of this one.
+func (c *cache[T]) fill(yield func(T) bool) {
+    gen := c.gen
+    c.filling = true
+    c.mu.Unlock()
 
-    var dynIter iter.Seq2[T1, T2]
-    cachedIter := func(yield func(T1, T2) bool) {
-        for _, v := range cached {
-            if !yield(v.V1, v.V2) {
-                return
-            }
+    exhausted := false
+    defer func() {
+        c.mu.Lock()
+        defer c.mu.Unlock()
+        if c.gen != gen || !c.filling {
+            return
+        }
+        c.filling = false
+        if exhausted {
+            c.complete = true
+            c.expiresAt = time.Now().Add(c.opts.TTL)
+        } else {
+            // start a new generation, so the replaying iterations fall back to the source instead of following the next filling iteration.
+            c.gen++
+            c.values = nil
+        }
+        c.cond.Broadcast()
+    }()
+
+    for v := range c.source {
+        c.add(gen, v)
+        if !yield(v) {
+            return
         }
     }
+    exhausted = true
+}
 
-    originalIter := func(yield func(T1, T2) bool) {
-        cTemp := make([]*Combined[T1, T2], 0)
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](it))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                break
-            }
-            if !yield(v1, v2) {
-                return
-            }
-            cTemp = append(cTemp, &Combined[T1, T2]{
-                V1: v1,
-                V2: v2,
-            })
+func (c *cache[T]) add(gen uint64, v T) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.gen != gen || !c.filling {
+        return
+    }
+
+    if c.opts.MaxElements > 0 && len(c.values) >= c.opts.MaxElements {
+        c.oversized = true
+        c.filling = false
+        c.values = nil
+    } else {
+        c.values = append(c.values, v)
+    }
+    c.cond.Broadcast()
+}
+
+// replay is called with mu held, it yields the values cached by the filling iteration as they come.
+func (c *cache[T]) replay(yield func(T) bool) {
+    gen := c.gen
+    n := 0
+    for {
+        for n >= len(c.values) && c.filling && c.gen == gen {
+            c.cond.Wait()
         }
-        if atomic.CompareAndSwapInt32(&cacheFlag, 0, 1) {
-            cached = cTemp
-            dynIter = cachedIter
+        if c.gen != gen || (!c.filling && !c.complete) {
+            // the filling iteration has stopped before the end, or the cache has been reset
+            c.mu.Unlock()
+            c.skip(n, yield)
+            return
+        }
+        if n >= len(c.values) {
+            c.mu.Unlock()
+            return
         }
-    }
-    dynIter = originalIter
-    sIter := Seq2Source(func() iter.Seq2[T1, T2] {
-        return dynIter
-    })
 
-    return func(yield func(T1, T2) bool) {
-        for v1, v2 := range sIter {
-            if !yield(v1, v2) {
+        // the values that have been cached are never modified, so they can be read without holding mu
+        values := c.values
+        c.mu.Unlock()
+        for ; n < len(values); n++ {
+            if !yield(values[n]) {
                 return
             }
         }
+        c.mu.Lock()
+    }
+}
+
+// skip iterates over the input iterator, skipping the first n values.
+func (c *cache[T]) skip(n int, yield func(T) bool) {
+    for v := range c.source {
+        if n > 0 {
+            n--
+            continue
+        }
+        if !yield(v) {
+            return
+        }
     }
 }
+
+func (c *cache[T]) invalidate() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.reset()
+}
+
+// reset is called with mu held.
+func (c *cache[T]) reset() {
+    c.gen++
+    c.values = nil
+    c.filling = false
+    c.complete = false
+    c.oversized = false
+    c.cond.Broadcast()
+}
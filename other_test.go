@@ -0,0 +1,356 @@
+package goiter
+
+import (
+    "fmt"
+    "slices"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestCacheWith(t *testing.T) {
+    count := 0
+    source := Iterator[int](func(yield func(int) bool) {
+        count++
+        for i := range 3 {
+            if !yield(i) {
+                return
+            }
+        }
+    })
+
+    // an iteration that breaks out of the loop does not fill the cache
+    cached := CacheWith(source, nil)
+    for _ = range cached.Iter() {
+        break
+    }
+    for range 2 {
+        actual := slices.Collect(cached.Iter().Seq())
+        if !slices.Equal([]int{0, 1, 2}, actual) {
+            t.Fatal(fmt.Sprintf("expect: [0 1 2], actual: %v", actual))
+        }
+    }
+    if count != 2 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 2, count))
+    }
+
+    // invalidate
+    cached.Invalidate()
+    _ = slices.Collect(cached.Iter().Seq())
+    _ = slices.Collect(cached.Iter().Seq())
+    if count != 3 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 3, count))
+    }
+
+    // too many elements to be cached
+    count = 0
+    cached = source.CacheWith(&CacheOptions{MaxElements: 2})
+    for range 2 {
+        actual := slices.Collect(cached.Iter().Seq())
+        if !slices.Equal([]int{0, 1, 2}, actual) {
+            t.Fatal(fmt.Sprintf("expect: [0 1 2], actual: %v", actual))
+        }
+    }
+    if count != 2 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 2, count))
+    }
+    count = 0
+    cached = source.CacheWith(&CacheOptions{MaxElements: 3})
+    _ = slices.Collect(cached.Iter().Seq())
+    _ = slices.Collect(cached.Iter().Seq())
+    if count != 1 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 1, count))
+    }
+
+    // expiry
+    count = 0
+    cached = source.CacheWith(&CacheOptions{TTL: 200 * time.Millisecond})
+    _ = slices.Collect(cached.Iter().Seq())
+    _ = slices.Collect(cached.Iter().Seq())
+    if count != 1 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 1, count))
+    }
+    time.Sleep(250 * time.Millisecond)
+    actual := slices.Collect(cached.Iter().Seq())
+    if !slices.Equal([]int{0, 1, 2}, actual) {
+        t.Fatal(fmt.Sprintf("expect: [0 1 2], actual: %v", actual))
+    }
+    _ = slices.Collect(cached.Iter().Seq())
+    if count != 2 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 2, count))
+    }
+}
+
+func TestCacheWith2(t *testing.T) {
+    count := 0
+    source := Iterator2[int, string](func(yield func(int, string) bool) {
+        count++
+        for i, v := range []string{"a", "b"} {
+            if !yield(i, v) {
+                return
+            }
+        }
+    })
+    cached := source.CacheWith(nil)
+    for range 2 {
+        actual := []string{}
+        for i, v := range cached.Iter() {
+            actual = append(actual, fmt.Sprintf("%d:%s", i, v))
+        }
+        if !slices.Equal([]string{"0:a", "1:b"}, actual) {
+            t.Fatal(fmt.Sprintf("expect: [0:a 1:b], actual: %v", actual))
+        }
+    }
+    if count != 1 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 1, count))
+    }
+    cached.Invalidate()
+    _ = Count2(cached.Iter())
+    if count != 2 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 2, count))
+    }
+}
+
+func TestCacheWith_ReplayPartial(t *testing.T) {
+    // the source waits for a signal before yielding each value
+    newSource := func(gate chan struct{}, count *int) Iterator[int] {
+        var mu sync.Mutex
+        return func(yield func(int) bool) {
+            mu.Lock()
+            *count++
+            mu.Unlock()
+            for i := range 3 {
+                <-gate
+                if !yield(i) {
+                    return
+                }
+            }
+        }
+    }
+    iterate := func(it Iterator[int], yielded chan<- int, done chan<- []int) {
+        actual := []int{}
+        for v := range it {
+            actual = append(actual, v)
+            yielded <- v
+        }
+        done <- actual
+    }
+
+    gate := make(chan struct{}, 10)
+    count := 0
+    cached := CacheWith(newSource(gate, &count), &CacheOptions{ReplayPartial: true})
+    firstYielded, secondYielded := make(chan int), make(chan int)
+    firstDone, secondDone := make(chan []int, 1), make(chan []int, 1)
+    gate <- struct{}{}
+    go iterate(cached.Iter(), firstYielded, firstDone)
+    <-firstYielded
+    // the value cached so far is replayed without waiting for the source
+    go iterate(cached.Iter(), secondYielded, secondDone)
+    if v := <-secondYielded; v != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", v))
+    }
+    for range 2 {
+        gate <- struct{}{}
+        <-firstYielded
+        <-secondYielded
+    }
+    for _, done := range []chan []int{firstDone, secondDone} {
+        if actual := <-done; !slices.Equal([]int{0, 1, 2}, actual) {
+            t.Fatal(fmt.Sprintf("expect: [0 1 2], actual: %v", actual))
+        }
+    }
+    if count != 1 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 1, count))
+    }
+
+    // without replaying, an iteration that starts while the cache is being filled reads the source on its own
+    count = 0
+    cached = CacheWith(newSource(gate, &count), nil)
+    started, release := make(chan struct{}), make(chan struct{})
+    go func() {
+        for v := range cached.Iter() {
+            if v == 0 {
+                close(started)
+                <-release
+            }
+        }
+        close(firstDone)
+    }()
+    gate <- struct{}{}
+    <-started
+    for range 3 {
+        gate <- struct{}{}
+    }
+    if actual := slices.Collect(cached.Iter().Seq()); !slices.Equal([]int{0, 1, 2}, actual) {
+        t.Fatal(fmt.Sprintf("expect: [0 1 2], actual: %v", actual))
+    }
+    gate <- struct{}{}
+    gate <- struct{}{}
+    close(release)
+    <-firstDone
+    if count != 2 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 2, count))
+    }
+}
+
+func TestCacheWith_ReplayPartialFallback(t *testing.T) {
+    gate := make(chan struct{}, 10)
+    count := 0
+    var mu sync.Mutex
+    source := Iterator[int](func(yield func(int) bool) {
+        mu.Lock()
+        count++
+        mu.Unlock()
+        for i := range 3 {
+            <-gate
+            if !yield(i) {
+                return
+            }
+        }
+    })
+    cached := source.CacheWith(&CacheOptions{ReplayPartial: true})
+
+    // the filling iteration breaks out of the loop after the second value, the replaying one carries on with the source on its own
+    reached, stop := make(chan struct{}), make(chan struct{})
+    replayed := make(chan int)
+    replayDone := make(chan []int)
+    go func() {
+        for v := range cached.Iter() {
+            if v == 1 {
+                close(reached)
+                <-stop
+                break
+            }
+        }
+    }()
+    gate <- struct{}{}
+    gate <- struct{}{}
+    <-reached
+    go func() {
+        actual := []int{}
+        for v := range cached.Iter() {
+            actual = append(actual, v)
+            replayed <- v
+        }
+        replayDone <- actual
+    }()
+    <-replayed
+    <-replayed
+    close(stop)
+    for range 3 {
+        gate <- struct{}{}
+    }
+    <-replayed
+    if actual := <-replayDone; !slices.Equal([]int{0, 1, 2}, actual) {
+        t.Fatal(fmt.Sprintf("expect: [0 1 2], actual: %v", actual))
+    }
+    if count != 2 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 2, count))
+    }
+}
+
+func TestCacheWith_ReplayPartialRefill(t *testing.T) {
+    count := atomic.Int32{}
+    source := Iterator[int](func(yield func(int) bool) {
+        count.Add(1)
+        for i := range 5 {
+            if !yield(i) {
+                return
+            }
+        }
+    })
+    cached := source.CacheWith(&CacheOptions{ReplayPartial: true})
+
+    // the first filling iteration breaks out of the loop while the replaying one is in its loop body,
+    // then another filling iteration starts and stalls after the first value before the replaying one goes on.
+    fillerAt0, fillerGo, fillerDone := make(chan struct{}), make(chan struct{}), make(chan struct{})
+    fillerBreak := make(chan struct{})
+    go func() {
+        for v := range cached.Iter() {
+            if v == 0 {
+                close(fillerAt0)
+                <-fillerGo
+            }
+            if v == 1 {
+                <-fillerBreak
+            }
+            if v == 2 {
+                break
+            }
+        }
+        close(fillerDone)
+    }()
+    <-fillerAt0
+    replayed, replayGo, replayDone := make(chan int), make(chan struct{}), make(chan []int, 1)
+    go func() {
+        actual := []int{}
+        for v := range cached.Iter() {
+            actual = append(actual, v)
+            if v <= 1 {
+                replayed <- v
+            }
+            if v == 1 {
+                <-replayGo
+            }
+        }
+        replayDone <- actual
+    }()
+    <-replayed
+    close(fillerGo)
+    <-replayed
+    close(fillerBreak)
+    <-fillerDone
+
+    refillerAt0, refillerGo, refillerDone := make(chan struct{}), make(chan struct{}), make(chan struct{})
+    go func() {
+        for v := range cached.Iter() {
+            if v == 0 {
+                close(refillerAt0)
+                <-refillerGo
+            }
+        }
+        close(refillerDone)
+    }()
+    <-refillerAt0
+
+    // the replaying iteration carries on with the source on its own instead of waiting for the stalled one
+    close(replayGo)
+    select {
+    case actual := <-replayDone:
+        if !slices.Equal([]int{0, 1, 2, 3, 4}, actual) {
+            t.Fatal(fmt.Sprintf("expect: [0 1 2 3 4], actual: %v", actual))
+        }
+    case <-time.After(time.Second):
+        t.Fatal("expect the replaying iteration to fall back to the source")
+    }
+    close(refillerGo)
+    <-refillerDone
+    if c := count.Load(); c != 3 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", 3, c))
+    }
+}
+
+func TestCacheWith_Concurrent(t *testing.T) {
+    for _, opts := range []*CacheOptions{nil, {ReplayPartial: true}, {MaxElements: 50}, {TTL: time.Millisecond}} {
+        cached := Range(0, 99).CacheWith(opts)
+        wg := sync.WaitGroup{}
+        for i := range 8 {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                for range 20 {
+                    if i == 0 {
+                        cached.Invalidate()
+                    }
+                    actual := slices.Collect(cached.Iter().Seq())
+                    if len(actual) != 100 || actual[0] != 0 || actual[99] != 99 {
+                        t.Error(fmt.Sprintf("unexpected values: %v", actual))
+                        return
+                    }
+                }
+            }()
+        }
+        wg.Wait()
+    }
+}
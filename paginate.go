@@ -0,0 +1,154 @@
+package goiter
+
+import (
+    "context"
+    "math"
+    "time"
+)
+
+// PageFetcher fetches the page identified by token, it returns the items of the page and the token of the next page.
+// The token of the first page is an empty string, and an empty next token means there are no more pages.
+type PageFetcher[T any] func(ctx context.Context, token string) (items []T, next string, err error)
+
+// PaginateOptions configures Paginate.
+type PaginateOptions struct {
+    // Prefetch makes Paginate fetch the next page in the background while the items of the current page are being yielded.
+    Prefetch bool
+    // MaxRetries is the number of times a failed fetch is retried before giving up, 0 means no retry.
+    MaxRetries int
+    // Backoff is the time to wait before the first retry, it doubles for each subsequent retry, 100ms is used if it is not positive.
+    Backoff time.Duration
+    // MaxBackoff caps the time to wait between retries, it is not capped if it is not positive.
+    MaxBackoff time.Duration
+}
+
+const defaultPaginateBackoff = 100 * time.Millisecond
+
+// Paginate returns an iterator that yields the items of a paginated source, such as a remote API using cursors or page tokens.
+// Pages are fetched lazily, a page is not fetched until the items of the previous page have been consumed, unless opts.Prefetch is true,
+// and the iteration ends when the next token is empty. If opts is nil, the default options are used.
+//
+// Since fetching may fail, the returned iterator yields 2-tuples of (item, error).
+// A failed fetch is retried with exponential backoff according to opts, if it still fails, or ctx is done,
+// the iterator yields the zero value along with the error and then stops.
+// For example:
+//
+//  users := goiter.Paginate(ctx, func(ctx context.Context, token string) ([]User, string, error) {
+//      resp, err := client.ListUsers(ctx, &ListUsersRequest{PageToken: token})
+//      if err != nil {
+//          return nil, "", err
+//      }
+//      return resp.Users, resp.NextPageToken, nil
+//  }, &goiter.PaginateOptions{Prefetch: true, MaxRetries: 3})
+//  for user, err := range users {
+//      if err != nil {
+//          return err
+//      }
+//      fmt.Println(user.Name)
+//  }
+func Paginate[T any](ctx context.Context, fetch PageFetcher[T], opts *PaginateOptions) Iterator2[T, error] {
+    var o PaginateOptions
+    if opts != nil {
+        o = *opts
+    }
+    if o.Backoff <= 0 {
+        o.Backoff = defaultPaginateBackoff
+    }
+
+    return func(yield func(T, error) bool) {
+        var zero T
+        ctx, cancel := context.WithCancel(ctx)
+        defer cancel()
+
+        p := &paginator[T]{fetch: fetch, opts: o}
+        page := p.start(ctx, "")
+        for {
+            result := <-page
+            if result.err != nil {
+                yield(zero, result.err)
+                return
+            }
+
+            var nextPage <-chan pageResult[T]
+            if result.next != "" && o.Prefetch {
+                nextPage = p.start(ctx, result.next)
+            }
+            for _, item := range result.items {
+                if !yield(item, nil) {
+                    return
+                }
+            }
+
+            if result.next == "" {
+                return
+            }
+            if nextPage == nil {
+                nextPage = p.start(ctx, result.next)
+            }
+            page = nextPage
+        }
+    }
+}
+
+type pageResult[T any] struct {
+    items []T
+    next  string
+    err   error
+}
+
+type paginator[T any] struct {
+    fetch PageFetcher[T]
+    opts  PaginateOptions
+}
+
+// start fetches a page, in the background if prefetching is enabled.
+// The channel is buffered, so the goroutine never blocks even if nobody receives the result after breaking out of the loop.
+func (p *paginator[T]) start(ctx context.Context, token string) <-chan pageResult[T] {
+    ch := make(chan pageResult[T], 1)
+    if p.opts.Prefetch {
+        go func() {
+            ch <- p.fetchWithRetry(ctx, token)
+        }()
+    } else {
+        ch <- p.fetchWithRetry(ctx, token)
+    }
+    return ch
+}
+
+func (p *paginator[T]) fetchWithRetry(ctx context.Context, token string) pageResult[T] {
+    backoff := p.opts.Backoff
+    for attempt := 0; ; attempt++ {
+        if err := ctx.Err(); err != nil {
+            return pageResult[T]{err: err}
+        }
+        items, next, err := p.fetch(ctx, token)
+        if err == nil {
+            return pageResult[T]{items: items, next: next}
+        }
+        if attempt >= p.opts.MaxRetries {
+            return pageResult[T]{err: err}
+        }
+
+        timer := time.NewTimer(backoff)
+        select {
+        case <-ctx.Done():
+            timer.Stop()
+            return pageResult[T]{err: ctx.Err()}
+        case <-timer.C:
+        }
+        backoff = nextBackoff(backoff, p.opts.MaxBackoff)
+    }
+}
+
+// nextBackoff doubles backoff, capped at maxBackoff if it is positive, and saturating at the maximum duration instead of overflowing.
+func nextBackoff(backoff, maxBackoff time.Duration) time.Duration {
+    if backoff > math.MaxInt64/2 {
+        backoff = math.MaxInt64
+    } else {
+        backoff *= 2
+    }
+    if maxBackoff > 0 && backoff > maxBackoff {
+        backoff = maxBackoff
+    }
+    return backoff
+}
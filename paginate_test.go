@@ -0,0 +1,215 @@
+package goiter
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+    "net/http"
+    "net/http/httptest"
+    "slices"
+    "strconv"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func newTestPages(pages [][]int) PageFetcher[int] {
+    return func(ctx context.Context, token string) ([]int, string, error) {
+        idx := 0
+        if token != "" {
+            idx, _ = strconv.Atoi(token)
+        }
+        next := ""
+        if idx+1 < len(pages) {
+            next = strconv.Itoa(idx + 1)
+        }
+        return pages[idx], next, nil
+    }
+}
+
+func TestPaginate(t *testing.T) {
+    pages := [][]int{{1, 2}, {}, {3, 4, 5}, {6}}
+    for _, prefetch := range []bool{false, true} {
+        actual := []int{}
+        for v, err := range Paginate(context.Background(), newTestPages(pages), &PaginateOptions{Prefetch: prefetch}) {
+            if err != nil {
+                t.Fatal("unexpected error:", err)
+            }
+            actual = append(actual, v)
+        }
+        expect := []int{1, 2, 3, 4, 5, 6}
+        if !slices.Equal(expect, actual) {
+            t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+        }
+    }
+
+    // pages are fetched lazily
+    fetched := int32(0)
+    fetch := newTestPages(pages)
+    counting := func(ctx context.Context, token string) ([]int, string, error) {
+        atomic.AddInt32(&fetched, 1)
+        return fetch(ctx, token)
+    }
+    for v, _ := range Paginate(context.Background(), counting, nil) {
+        if v == 2 {
+            break
+        }
+    }
+    if fetched != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1, actual: %d", fetched))
+    }
+}
+
+func TestPaginate_Prefetch(t *testing.T) {
+    secondStarted := make(chan struct{})
+    fetch := func(ctx context.Context, token string) ([]int, string, error) {
+        if token == "" {
+            return []int{1, 2}, "next", nil
+        }
+        close(secondStarted)
+        return []int{3}, "", nil
+    }
+
+    actual := []int{}
+    for v, err := range Paginate(context.Background(), fetch, &PaginateOptions{Prefetch: true}) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        if v == 1 {
+            // the second page is being fetched while the first page is consumed
+            select {
+            case <-secondStarted:
+            case <-time.After(time.Second):
+                t.Fatal("expect the second page to be prefetched")
+            }
+        }
+        actual = append(actual, v)
+    }
+    expect := []int{1, 2, 3}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+}
+
+func TestPaginate_Retry(t *testing.T) {
+    errTemporary := errors.New("temporary")
+    attempts := 0
+    fetch := func(ctx context.Context, token string) ([]int, string, error) {
+        attempts++
+        if attempts < 3 {
+            return nil, "", errTemporary
+        }
+        return []int{1}, "", nil
+    }
+
+    actual := []int{}
+    opts := &PaginateOptions{MaxRetries: 2, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+    for v, err := range Paginate(context.Background(), fetch, opts) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, v)
+    }
+    if !slices.Equal([]int{1}, actual) || attempts != 3 {
+        t.Fatal(fmt.Sprintf("expect: [1] 3, actual: %v %d", actual, attempts))
+    }
+
+    // gives up after MaxRetries
+    attempts = -10
+    count := 0
+    for _, err := range Paginate(context.Background(), fetch, opts) {
+        count++
+        if !errors.Is(err, errTemporary) {
+            t.Fatal("expect error:", errTemporary, "actual:", err)
+        }
+    }
+    if count != 1 || attempts != -7 {
+        t.Fatal(fmt.Sprintf("expect: 1 -7, actual: %d %d", count, attempts))
+    }
+}
+
+func TestNextBackoff(t *testing.T) {
+    if b := nextBackoff(100*time.Millisecond, 0); b != 200*time.Millisecond {
+        t.Fatal("expect: 200ms, actual:", b)
+    }
+    if b := nextBackoff(100*time.Millisecond, 150*time.Millisecond); b != 150*time.Millisecond {
+        t.Fatal("expect: 150ms, actual:", b)
+    }
+
+    // uncapped backoff saturates instead of overflowing
+    b := 100 * time.Millisecond
+    for range 100 {
+        b = nextBackoff(b, 0)
+        if b <= 0 {
+            t.Fatal("expect the backoff to stay positive, actual:", b)
+        }
+    }
+    if b != math.MaxInt64 {
+        t.Fatal("expect:", time.Duration(math.MaxInt64), "actual:", b)
+    }
+}
+
+func TestPaginate_Cancel(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    fetch := func(ctx context.Context, token string) ([]int, string, error) {
+        return nil, "", errors.New("always fails")
+    }
+    go func() {
+        time.Sleep(20 * time.Millisecond)
+        cancel()
+    }()
+
+    var lastErr error
+    for _, err := range Paginate(ctx, fetch, &PaginateOptions{MaxRetries: 100, Backoff: time.Hour}) {
+        lastErr = err
+    }
+    if !errors.Is(lastErr, context.Canceled) {
+        t.Fatal("expect error:", context.Canceled, "actual:", lastErr)
+    }
+}
+
+func TestPaginate_HTTP(t *testing.T) {
+    type page struct {
+        Items []string `json:"items"`
+        Next  string   `json:"next"`
+    }
+    pages := map[string]page{
+        "":   {Items: []string{"a", "b"}, Next: "p2"},
+        "p2": {Items: []string{"c"}},
+    }
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        _ = json.NewEncoder(w).Encode(pages[r.URL.Query().Get("token")])
+    }))
+    defer server.Close()
+
+    fetch := func(ctx context.Context, token string) ([]string, string, error) {
+        req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?token="+token, nil)
+        if err != nil {
+            return nil, "", err
+        }
+        resp, err := server.Client().Do(req)
+        if err != nil {
+            return nil, "", err
+        }
+        defer resp.Body.Close()
+        var p page
+        if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+            return nil, "", err
+        }
+        return p.Items, p.Next, nil
+    }
+
+    actual := []string{}
+    for v, err := range Paginate(context.Background(), fetch, &PaginateOptions{Prefetch: true}) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, v)
+    }
+    expect := []string{"a", "b", "c"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+}
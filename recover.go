@@ -0,0 +1,70 @@
+package goiter
+
+import (
+    "fmt"
+    "runtime/debug"
+)
+
+// PanicError is the error yielded by Recover when the input iterator panics.
+type PanicError struct {
+    // Value is the value passed to panic.
+    Value any
+    // Stack is the stack trace of the goroutine at the moment the panic was recovered, it includes the frames of the panicking function.
+    Stack []byte
+}
+
+func (e *PanicError) Error() string {
+    return fmt.Sprintf("goiter: recovered from panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, so errors.Is and errors.As work with it.
+func (e *PanicError) Unwrap() error {
+    if err, ok := e.Value.(error); ok {
+        return err
+    }
+    return nil
+}
+
+// Recover returns an iterator that yields the values of the input iterator as 2-tuples of (value, nil),
+// but if the input iterator panics, including panics raised by the callbacks of upstream operators such as the transformer of Transform,
+// it yields the zero value along with a *PanicError that carries the panic value and the stack trace, and then stops, instead of crashing the program.
+// Panics raised in the body of your own loop are not recovered, they keep propagating as usual.
+// If the input iterator panics after you break out of the loop, for example, in its deferred cleanup, the panic is recovered and discarded,
+// as there is no way to yield the error anymore.
+// For example:
+//
+//  iterator := goiter.Transform(goiter.Items(2, 1, 0), func(v int) int { return 10 / v })
+//  for v, err := range goiter.Recover(iterator) {  // yields (5, nil) (10, nil) (0, *PanicError) where the PanicError wraps the division by zero
+//      ...
+//  }
+//
+// A few operators, such as Zip, FinishOnce and Cursor, use iter.Pull to run the input iterator as a coroutine.
+// iter.Pull passes any panic of the coroutine back to the caller, so those panics arrive at Recover in the same way as in plain iterators.
+// Functions that run the input iterator in another goroutine, such as NewBroadcaster, are not covered,
+// a panic there cannot be recovered by Recover, so wrap the input iterator with Recover before handing it over if necessary.
+func Recover[TIter SeqX[T], T any](iterator TIter) Iterator2[T, error] {
+    return func(yield func(T, error) bool) {
+        inLoopBody, stopped := false, false
+        defer func() {
+            if inLoopBody {
+                // the panic comes from the loop body of the consumer, let it go.
+                return
+            }
+            if p := recover(); p != nil && !stopped {
+                var zero T
+                yield(zero, &PanicError{Value: p, Stack: debug.Stack()})
+            }
+            // if the consumer has stopped, the panic is swallowed, since yield must not be called again.
+        }()
+
+        for v := range iterator {
+            inLoopBody = true
+            ok := yield(v, nil)
+            inLoopBody = false
+            if !ok {
+                stopped = true
+                return
+            }
+        }
+    }
+}
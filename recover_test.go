@@ -0,0 +1,197 @@
+package goiter
+
+import (
+    "cmp"
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+    "testing"
+)
+
+func TestRecover(t *testing.T) {
+    iterator := Transform(Items(2, 1, 0, 5), func(v int) int { return 10 / v })
+
+    actual := []int{}
+    var lastErr error
+    count := 0
+    for v, err := range Recover(iterator) {
+        count++
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        actual = append(actual, v)
+    }
+    expect := []int{5, 10}
+    if !slices.Equal(expect, actual) || count != 3 {
+        t.Fatal(fmt.Sprintf("expect: %v 3, actual: %v %d", expect, actual, count))
+    }
+    var panicErr *PanicError
+    if !errors.As(lastErr, &panicErr) {
+        t.Fatal("expect a PanicError, actual:", lastErr)
+    }
+    if !strings.Contains(panicErr.Error(), "divide by zero") {
+        t.Fatal("unexpected error message:", panicErr.Error())
+    }
+    if !strings.Contains(string(panicErr.Stack), "TestRecover") {
+        t.Fatal("expect the stack trace to be recorded")
+    }
+    var runtimeErr interface{ RuntimeError() }
+    if !errors.As(lastErr, &runtimeErr) {
+        t.Fatal("expect the panic value to be unwrapped")
+    }
+
+    // a panic value that is not an error
+    for _, err := range Recover(Iterator[int](func(yield func(int) bool) {
+        panic("boom")
+    })) {
+        if !errors.As(err, &panicErr) || panicErr.Value != "boom" || panicErr.Unwrap() != nil {
+            t.Fatal("unexpected error:", err)
+        }
+    }
+
+    // breaking out of the loop
+    for _, _ = range Recover(Items(1, 2, 3)) {
+        break
+    }
+
+    // panics of the input iterator after breaking out of the loop are swallowed
+    for _, _ = range Recover(Iterator[int](func(yield func(int) bool) {
+        defer func() {
+            panic("cleanup failed")
+        }()
+        for _, v := range []int{1, 2, 3} {
+            if !yield(v) {
+                return
+            }
+        }
+    })) {
+        break
+    }
+
+    // panics in the loop body are not recovered
+    func() {
+        defer func() {
+            if recover() != "body" {
+                t.Fatal("expect the panic of the loop body to propagate")
+            }
+        }()
+        for _, _ = range Recover(Items(1, 2, 3)) {
+            panic("body")
+        }
+    }()
+}
+
+// TestPanicPropagation makes sure that panics are propagated to the consumer through the operators, including the ones that use iter.Pull,
+// both panics of the input iterator and panics of the loop body, and that Recover catches the former.
+func TestPanicPropagation(t *testing.T) {
+    // source yields 1 2 and then panics
+    source := Iterator[int](func(yield func(int) bool) {
+        for _, v := range []int{1, 2} {
+            if !yield(v) {
+                return
+            }
+        }
+        panic("source")
+    })
+    source2 := Iterator2[int, int](func(yield func(int, int) bool) {
+        for _, v := range []int{1, 2} {
+            if !yield(v, v) {
+                return
+            }
+        }
+        panic("source")
+    })
+    fromSeq2 := func(it Iterator2[int, int]) Iterator[int] {
+        return Transform21(it, func(v1, _ int) int { return v1 })
+    }
+
+    operators := map[string]func() Iterator[int]{
+        "Filter":      func() Iterator[int] { return Filter(source, func(int) bool { return true }) },
+        "Filter2":     func() Iterator[int] { return fromSeq2(Filter2(source2, func(int, int) bool { return true })) },
+        "OfType":      func() Iterator[int] { return OfType[int](source) },
+        "Take":        func() Iterator[int] { return Take(source, 10) },
+        "Take2":       func() Iterator[int] { return fromSeq2(Take2(source2, 10)) },
+        "TakeLast":    func() Iterator[int] { return TakeLast(source, 10) },
+        "TakeLast2":   func() Iterator[int] { return fromSeq2(TakeLast2(source2, 10)) },
+        "Skip":        func() Iterator[int] { return Skip(source, 1) },
+        "Skip2":       func() Iterator[int] { return fromSeq2(Skip2(source2, 1)) },
+        "SkipLast":    func() Iterator[int] { return SkipLast(source, 1) },
+        "SkipLast2":   func() Iterator[int] { return fromSeq2(SkipLast2(source2, 1)) },
+        "Distinct":    func() Iterator[int] { return Distinct(source) },
+        "DistinctV1":  func() Iterator[int] { return fromSeq2(DistinctV1(source2)) },
+        "DistinctV2":  func() Iterator[int] { return fromSeq2(DistinctV2(source2)) },
+        "DistinctBy":  func() Iterator[int] { return DistinctBy(source, func(v int) int { return v }) },
+        "Distinct2By": func() Iterator[int] { return fromSeq2(Distinct2By(source2, func(v, _ int) int { return v })) },
+        "Transform":   func() Iterator[int] { return Transform(source, func(v int) int { return v }) },
+        "Transform2":  func() Iterator[int] { return fromSeq2(Transform2(source2, func(v1, v2 int) (int, int) { return v1, v2 })) },
+        "Transform12": func() Iterator[int] { return fromSeq2(Transform12(source, func(v int) (int, int) { return v, v })) },
+        "Transform21": func() Iterator[int] { return fromSeq2(source2) },
+        "Scan":        func() Iterator[int] { return Scan(source, 0, func(acc, v int) int { return v }) },
+        "Zip":         func() Iterator[int] { return fromSeq2(Zip(source, Counter(0))) },
+        "ZipAs":       func() Iterator[int] { return ZipAs(source, Counter(0), func(z *Zipped[int, int]) int { return z.V1 }, false) },
+        "Reverse":     func() Iterator[int] { return Reverse(source) },
+        "Reverse2":    func() Iterator[int] { return fromSeq2(Reverse2(source2)) },
+        "Cache":       func() Iterator[int] { return Cache(source) },
+        "Cache2":      func() Iterator[int] { return fromSeq2(Cache2(source2)) },
+        "Once":        func() Iterator[int] { return Once(source) },
+        "Once2":       func() Iterator[int] { return fromSeq2(Once2(source2)) },
+        "FinishOnce":  func() Iterator[int] { return FinishOnce(source) },
+        "FinishOnce2": func() Iterator[int] { return fromSeq2(FinishOnce2(source2)) },
+        "Order":       func() Iterator[int] { return Order(source) },
+        "TopK":        func() Iterator[int] { return TopK(source, 1, cmp.Compare[int]) },
+        "Cursor":      func() Iterator[int] { return NewCursor(source).Rest() },
+    }
+    // operators that buffer everything before yielding, so the panic happens before the first value
+    buffering := map[string]bool{
+        "TakeLast": true, "TakeLast2": true, "Reverse": true, "Reverse2": true, "Order": true, "TopK": true,
+    }
+
+    for name, op := range operators {
+        // the panic of the input iterator reaches the consumer
+        func() {
+            defer func() {
+                if p := recover(); p != "source" {
+                    t.Fatal(fmt.Sprintf("%s: expect panic \"source\", actual: %v", name, p))
+                }
+            }()
+            for _ = range op() {
+            }
+            t.Fatal(fmt.Sprintf("%s: expect a panic", name))
+        }()
+
+        // Recover turns it into an error
+        var lastErr error
+        values := 0
+        for _, err := range Recover(op()) {
+            if err != nil {
+                lastErr = err
+            } else {
+                values++
+            }
+        }
+        var panicErr *PanicError
+        if !errors.As(lastErr, &panicErr) || panicErr.Value != "source" {
+            t.Fatal(fmt.Sprintf("%s: expect a PanicError, actual: %v", name, lastErr))
+        }
+        if !buffering[name] && values == 0 {
+            t.Fatal(fmt.Sprintf("%s: expect values before the panic", name))
+        }
+
+        // the panic of the loop body reaches the consumer as well, even through Recover
+        if buffering[name] {
+            continue
+        }
+        func() {
+            defer func() {
+                if p := recover(); p != "body" {
+                    t.Fatal(fmt.Sprintf("%s: expect panic \"body\", actual: %v", name, p))
+                }
+            }()
+            for _, _ = range Recover(op()) {
+                panic("body")
+            }
+        }()
+    }
+}
@@ -1,8 +1,12 @@
 package goiter
 
 import (
-    "iter"
+    "cmp"
+    "fmt"
     "math"
+    "math/rand/v2"
+    "slices"
+    "time"
 )
 
 type GeneratorFunc[T any] func() (T, bool)
@@ -14,6 +18,10 @@ type TInt interface {
     ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
 }
 
+type TFloat interface {
+    ~float32 | ~float64
+}
+
 // Range returns an iterator that yields a sequence of integers forward or backward from start to end, incrementing/decrementing by 1.
 // to be specific, the second parameter "end" is inclusive.
 // for example:
@@ -74,6 +82,260 @@ func RangeStep[T TInt, S TInt](start, end T, stepSize S) Iterator[T] {
     }
 }
 
+// IntRange is an integer range with a known length, so you can get its length and access its values by index without iterating over it.
+// It is created by RangeExclusive or RangeExclusiveStep.
+type IntRange[T TInt] struct {
+    start T
+    step  uint64
+    inc   bool
+    n     uint64
+}
+
+// RangeExclusive returns a half-open range of integers from start to stop, incrementing by 1, just like Python's range(start, stop).
+// So "stop" is exclusive and if start is greater than or equal to stop, the range is empty.
+// For example:
+//
+//  goiter.RangeExclusive(0, 5).Iter()  // will yield 0, 1, 2, 3, 4
+//  goiter.RangeExclusive(0, 5).Len()   // is 5
+func RangeExclusive[T TInt](start, stop T) IntRange[T] {
+    return RangeExclusiveStep(start, stop, 1)
+}
+
+// RangeExclusiveStep is RangeExclusive with a signed step, it follows the semantics of Python's range(start, stop, step),
+// which is different from RangeStep:
+//  1. the second parameter "stop" is exclusive.
+//  2. the sign of step decides the direction, so a negative step is needed to iterate backward, and the range is empty if step points away from stop.
+//  3. a step of 0 results in an empty range, rather than an error in Python.
+//
+// For example:
+//
+//  goiter.RangeExclusiveStep(0, 10, 3).Iter()  // will yield 0, 3, 6, 9
+//  goiter.RangeExclusiveStep(5, -5, -2).Iter() // will yield 5, 3, 1, -1, -3
+//  goiter.RangeExclusiveStep(0, 10, -1).Len()  // is 0
+//
+// Values never overflow T, since the length of the range is calculated upfront.
+func RangeExclusiveStep[T TInt, S TInt](start, stop T, step S) IntRange[T] {
+    r := IntRange[T]{start: start, inc: step > 0}
+    if step > 0 {
+        r.step = uint64(step)
+    } else {
+        // works for the minimum value of signed types as well, thanks to two's complement.
+        r.step = -uint64(step)
+    }
+    if step == 0 || (r.inc && start >= stop) || (!r.inc && start <= stop) {
+        return r
+    }
+    if willOverflow(start, r.step, r.inc) {
+        // like RangeStep, the first step already goes beyond T, so start is the only value
+        r.n = 1
+        return r
+    }
+
+    // willOverflow only tells whether a single step fits in T, while the length needs the number of steps between start and stop.
+    // the distance between start and stop always fits in uint64, even if subtracting them overflows T.
+    var distance uint64
+    if r.inc {
+        distance = uint64(stop) - uint64(start)
+    } else {
+        distance = uint64(start) - uint64(stop)
+    }
+    r.n = distance / r.step
+    if distance%r.step != 0 {
+        r.n++
+    }
+    return r
+}
+
+// Len returns the number of values in the range.
+// If the number does not fit in int, which is only possible with 64-bit types, math.MaxInt is returned, use Len64 to get the exact number.
+func (r IntRange[T]) Len() int {
+    if r.n > math.MaxInt {
+        return math.MaxInt
+    }
+    return int(r.n)
+}
+
+// Len64 is like Len, but it returns the exact number of values in the range, even if it does not fit in int.
+func (r IntRange[T]) Len64() uint64 {
+    return r.n
+}
+
+// At returns the i-th value of the range, it panics if i is out of range, just like indexing a slice.
+// Use At64 to access the values beyond math.MaxInt.
+func (r IntRange[T]) At(i int) T {
+    if i < 0 {
+        panic(fmt.Sprintf("goiter: index %d out of range [0:%d]", i, r.n))
+    }
+    return r.At64(uint64(i))
+}
+
+// At64 is like At, but it takes a uint64 index, so it can access all the values of a range whose length does not fit in int.
+func (r IntRange[T]) At64(i uint64) T {
+    if i >= r.n {
+        panic(fmt.Sprintf("goiter: index %d out of range [0:%d]", i, r.n))
+    }
+    if r.inc {
+        return T(uint64(r.start) + i*r.step)
+    }
+    return T(uint64(r.start) - i*r.step)
+}
+
+// Iter returns an iterator that yields the values of the range in order.
+func (r IntRange[T]) Iter() Iterator[T] {
+    return func(yield func(T) bool) {
+        // calculating in uint64 wraps around exactly like T does, and the length guarantees that no value goes beyond stop.
+        curr := uint64(r.start)
+        for i := uint64(0); i < r.n; i++ {
+            if !yield(T(curr)) {
+                return
+            }
+            if r.inc {
+                curr += r.step
+            } else {
+                curr -= r.step
+            }
+        }
+    }
+}
+
+// RangeFloat is the floating-point version of RangeStep, it shares the same semantics:
+// the second parameter "end" is inclusive, stepSize must be positive and the direction of iteration is decided by start and end.
+// Each value is computed as start + i*stepSize rather than by accumulating stepSize, so the floating-point errors do not pile up,
+// and "end" is still yielded when it is a multiple of stepSize away from start but the computation is slightly off because of rounding.
+// Only the last value is snapped to "end", the values in between carry the usual rounding errors of floating-point arithmetic.
+// For example:
+//
+//  goiter.RangeFloat(0, 1, 0.25)  // will yield 0, 0.25, 0.5, 0.75, 1
+//  goiter.RangeFloat(0.3, 0, 0.1) // will yield 0.3, 0.19999999999999998, 0.09999999999999998, 0
+//
+// If stepSize is not positive, or any parameter is NaN, it yields nothing.
+func RangeFloat[T TFloat](start, end, stepSize T) Iterator[T] {
+    s, e, step := float64(start), float64(end), float64(stepSize)
+    if !(step > 0) || math.IsNaN(s) || math.IsNaN(e) {
+        return Empty[T]()
+    }
+
+    dir := 1.0
+    if s > e {
+        dir = -1
+    }
+    tolerance := floatTolerance[T]()
+    count := math.Abs(e-s) / step
+    last := math.Floor(count)
+    if count-last > 1-tolerance {
+        last++
+    }
+
+    return func(yield func(T) bool) {
+        for i := 0.0; i <= last; i++ {
+            v := s + dir*i*step
+            if i == last && math.Abs(v-e) <= tolerance*math.Max(1, math.Abs(e)) {
+                v = e
+            }
+            if !yield(T(v)) {
+                return
+            }
+        }
+    }
+}
+
+// floatTolerance returns the relative error tolerated by RangeFloat when it decides whether "end" is reached,
+// float32 values carry much larger rounding errors than float64 ones, so they need a larger tolerance.
+func floatTolerance[T TFloat]() float64 {
+    // 1+1e-9 is rounded to 1 only if T is float32
+    if one := T(1); one+T(1e-9) == one {
+        return 1e-6
+    }
+    return 1e-9
+}
+
+// Linspace returns an iterator that yields n evenly spaced values over the closed interval from start to end, like numpy.linspace.
+// Both start and end are yielded when n is greater than 1, and if n is 1, only start is yielded.
+// For example:
+//
+//  goiter.Linspace(0, 1, 5)  // will yield 0, 0.25, 0.5, 0.75, 1
+//
+// If n is less than or equal to 0, it yields nothing.
+func Linspace[T TFloat](start, end T, n int) Iterator[T] {
+    if n <= 0 {
+        return Empty[T]()
+    }
+    s, e := float64(start), float64(end)
+    return func(yield func(T) bool) {
+        if n == 1 {
+            yield(start)
+            return
+        }
+        step := (e - s) / float64(n-1)
+        for i := 0; i < n-1; i++ {
+            if !yield(T(s + float64(i)*step)) {
+                return
+            }
+        }
+        yield(end)
+    }
+}
+
+// TimeRange returns an iterator that yields points in time from start to end, stepping by a fixed duration,
+// it shares the same semantics as RangeStep: "end" is inclusive, step must be positive, and the direction of iteration is decided by start and end.
+// For example:
+//
+//  goiter.TimeRange(t, t.Add(time.Hour), 15*time.Minute) // will yield t, t+15m, t+30m, t+45m, t+1h
+//
+// If step is not positive, it yields nothing.
+// Note that a fixed duration does not always match a calendar day, because of daylight saving time, use DateRange for calendar stepping.
+func TimeRange(start, end time.Time, step time.Duration) Iterator[time.Time] {
+    if step <= 0 {
+        return Empty[time.Time]()
+    }
+    if start.After(end) {
+        step = -step
+    }
+    return func(yield func(time.Time) bool) {
+        // stepping from the previous value is exact with integer durations, and unlike start.Add(i*step), i*step cannot overflow on long ranges
+        for t := start; ; t = t.Add(step) {
+            if (step > 0 && t.After(end)) || (step < 0 && t.Before(end)) {
+                return
+            }
+            if !yield(t) {
+                return
+            }
+        }
+    }
+}
+
+// DateRange returns an iterator that yields dates from start to end, stepping by calendar months and days,
+// it shares the same semantics as RangeStep: "end" is inclusive, steps must not be negative, and the direction of iteration is decided by start and end.
+// The i-th value is computed from start by adding i*months months and then i*days days, and when the target month is shorter,
+// the day of month is clamped to its last day instead of overflowing into the next month. The time of day and the location of start are kept.
+// For example:
+//
+//  jan31 := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+//  goiter.DateRange(jan31, jan31.AddDate(0, 3, 0), 1, 0)   // will yield 2024-01-31, 2024-02-29, 2024-03-31, 2024-04-30
+//  goiter.DateRange(jan31, jan31.AddDate(0, 0, 14), 0, 7)  // will yield 2024-01-31, 2024-02-07, 2024-02-14
+//
+// If either step is negative or both are 0, it yields nothing.
+func DateRange(start, end time.Time, months, days int) Iterator[time.Time] {
+    if months < 0 || days < 0 || (months == 0 && days == 0) {
+        return Empty[time.Time]()
+    }
+    dir := 1
+    if start.After(end) {
+        dir = -1
+    }
+    return func(yield func(time.Time) bool) {
+        for i := 0; ; i++ {
+            t := addDateClamped(start, dir*i*months, dir*i*days)
+            if (dir > 0 && t.After(end)) || (dir < 0 && t.Before(end)) {
+                return
+            }
+            if !yield(t) {
+                return
+            }
+        }
+    }
+}
+
 // Counter returns an iterator that yields a sequence of integers incrementing by 1.
 func Counter(startFrom int) Iterator[int] {
     var next = startFrom
@@ -132,6 +394,224 @@ func Sequence2[T1, T2 any](generator func() (T1, T2, bool)) Iterator2[T1, T2] {
     }
 }
 
+// WeightedChoice returns an infinite iterator that randomly draws items, each item is drawn with a probability proportional to its weight.
+// The input iterator yields 2-tuples of (item, weight), items with a weight that is not a positive finite number are ignored,
+// so if there is no item left, the returned iterator yields nothing.
+// rng works the same as in Shuffle.
+// It uses the alias method, so after an O(n) setup at the start of the iteration, drawing each item takes constant time.
+// For example:
+//
+//	weights := goiter.Map(map[string]float64{"GET": 8, "POST": 2})
+//	for method := range goiter.WeightedChoice(weights, rng).Take(100) {
+//		// about 80 of the 100 methods are "GET"
+//	}
+func WeightedChoice[TIter Seq2X[T, float64], T any](items TIter, rng *rand.Rand) Iterator[T] {
+    return func(yield func(T) bool) {
+        r := iterationRand(rng)
+        values, weights := collectWeighted(items)
+        if len(values) == 0 {
+            return
+        }
+        prob, alias := buildAliasTable(weights)
+        for {
+            i := r.IntN(len(values))
+            if r.Float64() >= prob[i] {
+                i = alias[i]
+            }
+            if !yield(values[i]) {
+                return
+            }
+        }
+    }
+}
+
+// WeightedChoiceWithoutReplacement is like WeightedChoice, but each item is drawn at most once,
+// so the returned iterator ends once all the items have been drawn, it yields a weighted random permutation of the items.
+func WeightedChoiceWithoutReplacement[TIter Seq2X[T, float64], T any](items TIter, rng *rand.Rand) Iterator[T] {
+    return func(yield func(T) bool) {
+        r := iterationRand(rng)
+        values, weights := collectWeighted(items)
+
+        // Efraimidis–Spirakis: ordering the items by u^(1/w) in descending order is equivalent to drawing them one by one without replacement.
+        keyed := make([]Combined[float64, T], len(values))
+        for i, v := range values {
+            keyed[i] = Combined[float64, T]{V1: math.Log(1-r.Float64()) / weights[i], V2: v}
+        }
+        slices.SortStableFunc(keyed, func(a, b Combined[float64, T]) int {
+            return cmp.Compare(b.V1, a.V1)
+        })
+        for _, each := range keyed {
+            if !yield(each.V2) {
+                return
+            }
+        }
+    }
+}
+
+func collectWeighted[TIter Seq2X[T, float64], T any](items TIter) ([]T, []float64) {
+    values := make([]T, 0)
+    weights := make([]float64, 0)
+    for v, w := range items {
+        if !(w > 0) || math.IsInf(w, 1) {
+            continue
+        }
+        values = append(values, v)
+        weights = append(weights, w)
+    }
+    return values, weights
+}
+
+// buildAliasTable builds the tables of the alias method using Vose's algorithm.
+func buildAliasTable(weights []float64) ([]float64, []int) {
+    n := len(weights)
+    total := 0.0
+    for _, w := range weights {
+        total += w
+    }
+
+    prob := make([]float64, n)
+    alias := make([]int, n)
+    scaled := make([]float64, n)
+    small := make([]int, 0, n)
+    large := make([]int, 0, n)
+    for i, w := range weights {
+        scaled[i] = w * float64(n) / total
+        if scaled[i] < 1 {
+            small = append(small, i)
+        } else {
+            large = append(large, i)
+        }
+    }
+
+    for len(small) > 0 && len(large) > 0 {
+        s := small[len(small)-1]
+        small = small[:len(small)-1]
+        l := large[len(large)-1]
+        large = large[:len(large)-1]
+
+        prob[s] = scaled[s]
+        alias[s] = l
+        scaled[l] = scaled[l] + scaled[s] - 1
+        if scaled[l] < 1 {
+            small = append(small, l)
+        } else {
+            large = append(large, l)
+        }
+    }
+    // what remains should have a probability of 1, anything else is caused by floating-point rounding errors.
+    for _, i := range large {
+        prob[i] = 1
+    }
+    for _, i := range small {
+        prob[i] = 1
+    }
+
+    return prob, alias
+}
+
+// Iterate returns an infinite iterator that yields seed, f(seed), f(f(seed)) and so on.
+// For example:
+//
+//  goiter.Iterate(1, func(v int) int { return v * 2 }) // will yield 1, 2, 4, 8, 16 ...
+func Iterate[T any](seed T, f func(T) T) Iterator[T] {
+    return func(yield func(T) bool) {
+        for v := seed; ; v = f(v) {
+            if !yield(v) {
+                return
+            }
+        }
+    }
+}
+
+// Iterate2 is the iter.Seq2 version of Iterate function.
+func Iterate2[T1, T2 any](seed1 T1, seed2 T2, f func(T1, T2) (T1, T2)) Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        for v1, v2 := seed1, seed2; ; v1, v2 = f(v1, v2) {
+            if !yield(v1, v2) {
+                return
+            }
+        }
+    }
+}
+
+// Unfold is the opposite of Reduce, it builds a sequence from an initial state.
+// In each round, f takes the current state and returns the value to yield, the next state, and whether there is a value at all.
+// Unlike Sequence, the state is passed around explicitly, so every iteration starts over from the initial state.
+// For example, the Fibonacci sequence:
+//
+//  fib := goiter.Unfold([2]int{0, 1}, func(s [2]int) (int, [2]int, bool) {
+//      return s[1], [2]int{s[1], s[0] + s[1]}, true
+//  })  // will yield 1, 1, 2, 3, 5, 8 ...
+func Unfold[S, T any](state S, f func(S) (T, S, bool)) Iterator[T] {
+    return func(yield func(T) bool) {
+        s := state
+        for {
+            v, next, ok := f(s)
+            if !ok {
+                return
+            }
+            if !yield(v) {
+                return
+            }
+            s = next
+        }
+    }
+}
+
+// Unfold2 is the iter.Seq2 version of Unfold function.
+func Unfold2[S, T1, T2 any](state S, f func(S) (T1, T2, S, bool)) Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        s := state
+        for {
+            v1, v2, next, ok := f(s)
+            if !ok {
+                return
+            }
+            if !yield(v1, v2) {
+                return
+            }
+            s = next
+        }
+    }
+}
+
+// Repeat returns an iterator that yields v n times, if n is negative, it yields v forever.
+func Repeat[T any](v T, n int) Iterator[T] {
+    return func(yield func(T) bool) {
+        for i := 0; n < 0 || i < n; i++ {
+            if !yield(v) {
+                return
+            }
+        }
+    }
+}
+
+// Cycle returns an iterator that yields the values of the input iterator over and over again forever, the input iterator must be finite.
+// Its values are cached by the Cache function once an iteration has traversed it to the end, and replayed afterward.
+// Like Cache, the values are not kept if you break out of the loop during the first pass, the next iteration traverses the input iterator again,
+// so an iterator that cannot be traversed twice yields nothing after that. Make sure the first pass completes if you use Cycle on such iterators.
+// If the input iterator yields nothing, Cycle yields nothing as well.
+// For example:
+//
+//  goiter.Cycle(goiter.Items(1, 2, 3)) // will yield 1, 2, 3, 1, 2, 3, 1 ...
+func Cycle[TIter SeqX[T], T any](iterator TIter) Iterator[T] {
+    cached := Cache(iterator)
+    return func(yield func(T) bool) {
+        for {
+            empty := true
+            for v := range cached {
+                empty = false
+                if !yield(v) {
+                    return
+                }
+            }
+            if empty {
+                return
+            }
+        }
+    }
+}
+
 // Reverse returns an iterator that yields the values of the input iterator in reverse order.
 // So if the input iterator yields "a" "b" "c", then goiter.Reverse(iterator) will yield "c" "b" "a".
 //
@@ -139,13 +619,7 @@ func Sequence2[T1, T2 any](generator func() (T1, T2, bool)) Iterator2[T1, T2] {
 func Reverse[TIter SeqX[T], T any](iterator TIter) Iterator[T] {
     return func(yield func(T) bool) {
         var buffer []T
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                break
-            }
+        for v := range iterator {
             buffer = append(buffer, v)
         }
         for i := len(buffer) - 1; i >= 0; i-- {
@@ -160,15 +634,9 @@ func Reverse[TIter SeqX[T], T any](iterator TIter) Iterator[T] {
 // be careful, if this function is used on iterators that has massive amount of data, it might consume a lot of memory.
 func Reverse2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter) Iterator2[T1, T2] {
     return func(yield func(T1, T2) bool) {
-        var buffer []*Combined[T1, T2]
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                break
-            }
-            buffer = append(buffer, &Combined[T1, T2]{V1: v1, V2: v2})
+        var buffer []Combined[T1, T2]
+        for v1, v2 := range iterator {
+            buffer = append(buffer, Combined[T1, T2]{V1: v1, V2: v2})
         }
         for i := len(buffer) - 1; i >= 0; i-- {
             if !yield(buffer[i].V1, buffer[i].V2) {
@@ -178,11 +646,18 @@ func Reverse2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter) Iterator2[T1, T2]
     }
 }
 
-func willOverflow[T TInt](v T, step uint64, inc bool) bool {
-    tMax := int64(tMax(v))
-    tMin := int64(tMin(v))
+func addDateClamped(t time.Time, months, days int) time.Time {
+    year, month, day := t.Date()
+    hour, minute, sec := t.Clock()
+    // day 0 of the next month is the last day of the target month
+    lastDay := time.Date(year, month+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+    return time.Date(year, month+time.Month(months), min(day, lastDay), hour, minute, sec, t.Nanosecond(), t.Location()).AddDate(0, 0, days)
+}
 
-    if tMax != math.MaxInt64 && step >= uint64(tMax-tMin+1) {
+func willOverflow[T TInt](v T, step uint64, inc bool) bool {
+    // span is the distance between the minimum and the maximum of T, which fits in uint64 even for 64-bit types
+    span := uint64(tMax(v)) - uint64(tMin(v))
+    if step > span {
         return true
     }
     if inc && v+T(step) < v {
@@ -3,8 +3,10 @@ package goiter
 import (
     "fmt"
     "math"
+    "math/rand/v2"
     "slices"
     "testing"
+    "time"
 )
 
 func TestRangeStep(t *testing.T) {
@@ -40,6 +42,15 @@ func TestRangeStep(t *testing.T) {
         t.Fatalf("test RangeStep failed, expect %d, got %v", expect, actual)
     }
 
+    actualUint64 := make([]uint64, 0)
+    for each := range RangeStep(uint64(1), uint64(5), 2) {
+        actualUint64 = append(actualUint64, each)
+    }
+    expectUint64 := []uint64{1, 3, 5}
+    if !slices.Equal(expectUint64, actualUint64) {
+        t.Fatalf("test RangeStep failed, expect %d, got %v", expectUint64, actualUint64)
+    }
+
     actualUint8 := make([]uint8, 0)
     for each := range RangeStep(uint8(100), uint8(251), 50) {
         actualUint8 = append(actualUint8, each)
@@ -387,3 +398,512 @@ func TestIntMin(t *testing.T) {
         t.Fatalf("test int64 expect %d, got %d", int64(math.MinInt64), tMin(int64(0)))
     }
 }
+
+func TestWeightedChoice(t *testing.T) {
+    weights := map[string]float64{"a": 1, "b": 3, "c": 6, "zero": 0, "negative": -1, "nan": math.NaN()}
+    counts := map[string]int{}
+    for v := range WeightedChoice(Map(weights), rand.New(rand.NewPCG(1, 2))).Take(100000) {
+        counts[v]++
+    }
+    if len(counts) != 3 {
+        t.Fatal(fmt.Sprintf("expect only positive weighted items to be drawn, actual: %v", counts))
+    }
+    for k, expect := range map[string]int{"a": 10000, "b": 30000, "c": 60000} {
+        if math.Abs(float64(counts[k]-expect)) > float64(expect)/20 {
+            t.Fatal(fmt.Sprintf("expect about %d %s, actual: %v", expect, k, counts))
+        }
+    }
+
+    actual1 := []int{}
+    for v := range WeightedChoice(Slice([]float64{1, 2, 3}), rand.New(rand.NewPCG(3, 4))).Take(20) {
+        actual1 = append(actual1, v)
+    }
+    actual2 := []int{}
+    for v := range WeightedChoice(Slice([]float64{1, 2, 3}), rand.New(rand.NewPCG(3, 4))).Take(20) {
+        actual2 = append(actual2, v)
+    }
+    if !slices.Equal(actual1, actual2) {
+        t.Fatal(fmt.Sprintf("expect the same seed to produce the same result: %v, %v", actual1, actual2))
+    }
+
+    if c := Count(WeightedChoice(Map(map[string]float64{"a": 0}), nil)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+}
+
+func TestWeightedChoiceWithoutReplacement(t *testing.T) {
+    weights := []float64{1, 0, 1000, 1}
+    first := map[int]int{}
+    rng := rand.New(rand.NewPCG(1, 2))
+    for range 1000 {
+        actual := []int{}
+        for v := range WeightedChoiceWithoutReplacement(Slice(weights), rng) {
+            actual = append(actual, v)
+        }
+        sorted := slices.Clone(actual)
+        slices.Sort(sorted)
+        if !slices.Equal([]int{0, 2, 3}, sorted) {
+            t.Fatal(fmt.Sprintf("expect each item to be drawn exactly once, actual: %v", actual))
+        }
+        first[actual[0]]++
+    }
+    if first[2] < 990 {
+        t.Fatal(fmt.Sprintf("expect the heaviest item to be drawn first most of the time, actual: %v", first))
+    }
+
+    for _ = range WeightedChoiceWithoutReplacement(Slice(weights), nil) {
+        break
+    }
+}
+
+func TestRangeFloat(t *testing.T) {
+    actual := []float64{}
+    for v := range RangeFloat(0, 1, 0.25) {
+        actual = append(actual, v)
+    }
+    expect := []float64{0, 0.25, 0.5, 0.75, 1}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    // 3*0.1 is 0.30000000000000004, but end is still yielded
+    actual = []float64{}
+    for v := range RangeFloat(0, 0.3, 0.1) {
+        actual = append(actual, v)
+    }
+    expect = []float64{0, 0.1, 0.2, 0.3}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    // no accumulated error
+    count := 0
+    last := 0.0
+    for v := range RangeFloat(0.0, 100, 0.1) {
+        count++
+        last = v
+    }
+    if count != 1001 || last != 100 {
+        t.Fatal(fmt.Sprintf("expect: 1001 100, actual: %d %v", count, last))
+    }
+
+    actual32 := []float32{}
+    for v := range RangeFloat[float32](1, -0.5, 0.5) {
+        actual32 = append(actual32, v)
+    }
+    expect32 := []float32{1, 0.5, 0, -0.5}
+    if !slices.Equal(expect32, actual32) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect32, actual32))
+    }
+
+    // 0.7/0.1 is 6.9999998 in float32, but end is still yielded
+    actual32 = []float32{}
+    for v := range RangeFloat[float32](0, 0.7, 0.1) {
+        actual32 = append(actual32, v)
+    }
+    if len(actual32) != 8 || actual32[7] != 0.7 {
+        t.Fatal(fmt.Sprintf("unexpected values: %v", actual32))
+    }
+
+    actual = []float64{}
+    for v := range RangeFloat(0, 1, 0.4) {
+        actual = append(actual, v)
+    }
+    expect = []float64{0, 0.4, 0.8}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(RangeFloat(0.0, 1, 0)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(RangeFloat(0, 1, -0.1)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(RangeFloat(math.NaN(), 1, 0.1)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    for _ = range RangeFloat(0, 1, 0.1) {
+        break
+    }
+}
+
+func TestLinspace(t *testing.T) {
+    actual := []float64{}
+    for v := range Linspace(0.0, 1, 5) {
+        actual = append(actual, v)
+    }
+    expect := []float64{0, 0.25, 0.5, 0.75, 1}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = []float64{}
+    for v := range Linspace(2.0, -2, 3) {
+        actual = append(actual, v)
+    }
+    expect = []float64{2, 0, -2}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = []float64{}
+    for v := range Linspace(0.1, 0.7, 7) {
+        actual = append(actual, v)
+    }
+    if len(actual) != 7 || actual[0] != 0.1 || actual[6] != 0.7 {
+        t.Fatal(fmt.Sprintf("unexpected values: %v", actual))
+    }
+
+    actual = []float64{}
+    for v := range Linspace(3.0, 5, 1) {
+        actual = append(actual, v)
+    }
+    expect = []float64{3}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(Linspace(0.0, 1, 0)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    for _ = range Linspace(0.0, 1, 10) {
+        break
+    }
+}
+
+func TestTimeRange(t *testing.T) {
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    actual := []time.Time{}
+    for v := range TimeRange(start, start.Add(time.Hour), 20*time.Minute) {
+        actual = append(actual, v)
+    }
+    expect := []time.Time{start, start.Add(20 * time.Minute), start.Add(40 * time.Minute), start.Add(time.Hour)}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = []time.Time{}
+    for v := range TimeRange(start, start.Add(-time.Hour), 25*time.Minute) {
+        actual = append(actual, v)
+    }
+    expect = []time.Time{start, start.Add(-25 * time.Minute), start.Add(-50 * time.Minute)}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    // longer than the maximum time.Duration, about 292 years
+    from := time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC)
+    to := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+    count := 0
+    prev := from.Add(-24 * time.Hour)
+    for v := range TimeRange(from, to, 24*time.Hour) {
+        if v.Sub(prev) != 24*time.Hour {
+            t.Fatal(fmt.Sprintf("unexpected value %v after %v", v, prev))
+        }
+        prev = v
+        count++
+    }
+    // 400 years have 146097 days
+    if count != 146098 || !prev.Equal(to) {
+        t.Fatal(fmt.Sprintf("expect: 146098 %v, actual: %d %v", to, count, prev))
+    }
+
+    if c := Count(TimeRange(start, start.Add(time.Hour), 0)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    for _ = range TimeRange(start, start.Add(time.Hour), time.Minute) {
+        break
+    }
+}
+
+func TestDateRange(t *testing.T) {
+    format := func(it Iterator[time.Time]) []string {
+        result := []string{}
+        for v := range it {
+            result = append(result, v.Format(time.DateOnly))
+        }
+        return result
+    }
+
+    jan31 := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+    actual := format(DateRange(jan31, time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), 1, 0))
+    expect := []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30", "2024-05-31"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = format(DateRange(jan31, jan31.AddDate(0, 0, 14), 0, 7))
+    expect = []string{"2024-01-31", "2024-02-07", "2024-02-14"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = format(DateRange(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), 1, 0))
+    expect = []string{"2024-03-31", "2024-02-29", "2024-01-31", "2023-12-31"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual = format(DateRange(jan31, time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), 1, 1))
+    expect = []string{"2024-01-31", "2024-03-01", "2024-04-02"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(DateRange(jan31, jan31.AddDate(1, 0, 0), 0, 0)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(DateRange(jan31, jan31.AddDate(1, 0, 0), -1, 0)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    for _ = range DateRange(jan31, jan31.AddDate(1, 0, 0), 1, 0) {
+        break
+    }
+}
+
+func TestRangeExclusive(t *testing.T) {
+    actual := []int{}
+    for v := range RangeExclusive(0, 5).Iter() {
+        actual = append(actual, v)
+    }
+    expect := []int{0, 1, 2, 3, 4}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    r := RangeExclusive(5, 0)
+    if r.Len() != 0 || Count(r.Iter()) != 0 {
+        t.Fatal(fmt.Sprintf("expect an empty range, actual: %d", r.Len()))
+    }
+}
+
+func TestRangeExclusiveStep(t *testing.T) {
+    testCases := []struct {
+        start, stop, step int
+        expect            []int
+    }{
+        {0, 10, 3, []int{0, 3, 6, 9}},
+        {0, 9, 3, []int{0, 3, 6}},
+        {5, -5, -2, []int{5, 3, 1, -1, -3}},
+        {0, 10, -1, []int{}},
+        {10, 0, 1, []int{}},
+        {0, 10, 0, []int{}},
+        {3, 3, 1, []int{}},
+    }
+    for _, tc := range testCases {
+        r := RangeExclusiveStep(tc.start, tc.stop, tc.step)
+        actual := []int{}
+        for v := range r.Iter() {
+            actual = append(actual, v)
+        }
+        if !slices.Equal(tc.expect, actual) {
+            t.Fatal(fmt.Sprintf("range(%d, %d, %d) expect: %v, actual: %v", tc.start, tc.stop, tc.step, tc.expect, actual))
+        }
+        if r.Len() != len(tc.expect) {
+            t.Fatal(fmt.Sprintf("range(%d, %d, %d) expect length: %d, actual: %d", tc.start, tc.stop, tc.step, len(tc.expect), r.Len()))
+        }
+        for i, v := range tc.expect {
+            if r.At(i) != v {
+                t.Fatal(fmt.Sprintf("range(%d, %d, %d) expect At(%d): %d, actual: %d", tc.start, tc.stop, tc.step, i, v, r.At(i)))
+            }
+        }
+    }
+
+    // no overflow
+    actual8 := []int8{}
+    for v := range RangeExclusiveStep[int8](-128, 127, 200).Iter() {
+        actual8 = append(actual8, v)
+    }
+    expect8 := []int8{-128, 72}
+    if !slices.Equal(expect8, actual8) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect8, actual8))
+    }
+
+    actualU8 := []uint8{}
+    for v := range RangeExclusiveStep[uint8](255, 0, -100).Iter() {
+        actualU8 = append(actualU8, v)
+    }
+    expectU8 := []uint8{255, 155, 55}
+    if !slices.Equal(expectU8, actualU8) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectU8, actualU8))
+    }
+
+    r := RangeExclusiveStep[int64](math.MinInt64, math.MaxInt64, math.MinInt64)
+    if r.Len() != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", r.Len()))
+    }
+    r = RangeExclusiveStep[int64](math.MaxInt64, math.MinInt64, math.MinInt64)
+    if r.Len() != 2 || r.At(1) != -1 {
+        t.Fatal(fmt.Sprintf("expect: 2 -1, actual: %d %d", r.Len(), r.At(1)))
+    }
+    full := RangeExclusive[uint64](0, math.MaxUint64)
+    if l := full.Len(); l != math.MaxInt {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", math.MaxInt, l))
+    }
+    if l := full.Len64(); l != math.MaxUint64 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", uint64(math.MaxUint64), l))
+    }
+    if v := full.At64(math.MaxUint64 - 1); v != math.MaxUint64-1 {
+        t.Fatal(fmt.Sprintf("expect: %d, actual: %d", uint64(math.MaxUint64-1), v))
+    }
+    // a step that goes beyond T right away
+    if r8 := RangeExclusiveStep[int8](100, 127, 50); r8.Len() != 1 || r8.At(0) != 100 {
+        t.Fatal(fmt.Sprintf("expect: 1 100, actual: %d", r8.Len()))
+    }
+    if l := RangeExclusive[uint64](1, 5).Len(); l != 4 {
+        t.Fatal(fmt.Sprintf("expect: 4, actual: %d", l))
+    }
+    ru := RangeExclusive[uint64](math.MaxUint64-2, math.MaxUint64)
+    if ru.Len() != 2 || ru.At(1) != math.MaxUint64-1 {
+        t.Fatal(fmt.Sprintf("expect: 2 %d, actual: %d %d", uint64(math.MaxUint64-1), ru.Len(), ru.At(1)))
+    }
+
+    for _ = range RangeExclusive(0, 10).Iter() {
+        break
+    }
+
+    defer func() {
+        if recover() == nil {
+            t.Fatal("expect At to panic when index is out of range")
+        }
+    }()
+    RangeExclusive(0, 3).At(3)
+}
+
+func TestIterate(t *testing.T) {
+    actual := []int{}
+    for v := range Iterate(1, func(v int) int { return v * 2 }).Take(5) {
+        actual = append(actual, v)
+    }
+    expect := []int{1, 2, 4, 8, 16}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    actual1 := []int{}
+    actual2 := []int{}
+    for v1, v2 := range Iterate2(0, 1, func(a, b int) (int, int) { return b, a + b }).Take(6) {
+        actual1 = append(actual1, v1)
+        actual2 = append(actual2, v2)
+    }
+    expect1 := []int{0, 1, 1, 2, 3, 5}
+    expect2 := []int{1, 1, 2, 3, 5, 8}
+    if !slices.Equal(expect1, actual1) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect1, actual1))
+    }
+    if !slices.Equal(expect2, actual2) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect2, actual2))
+    }
+}
+
+func TestUnfold(t *testing.T) {
+    fib := Unfold([2]int{0, 1}, func(s [2]int) (int, [2]int, bool) {
+        return s[1], [2]int{s[1], s[0] + s[1]}, s[1] < 10
+    })
+    expect := []int{1, 1, 2, 3, 5, 8}
+    // every iteration starts over from the initial state
+    for range 2 {
+        actual := []int{}
+        for v := range fib {
+            actual = append(actual, v)
+        }
+        if !slices.Equal(expect, actual) {
+            t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+        }
+    }
+    for _ = range fib {
+        break
+    }
+
+    actualKeys := []string{}
+    actualVals := []int{}
+    for k, v := range Unfold2(3, func(n int) (string, int, int, bool) {
+        return fmt.Sprintf("k%d", n), n * n, n - 1, n > 0
+    }) {
+        actualKeys = append(actualKeys, k)
+        actualVals = append(actualVals, v)
+    }
+    expectKeys := []string{"k3", "k2", "k1"}
+    expectVals := []int{9, 4, 1}
+    if !slices.Equal(expectKeys, actualKeys) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectKeys, actualKeys))
+    }
+    if !slices.Equal(expectVals, actualVals) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectVals, actualVals))
+    }
+    for _, _ = range Unfold2(3, func(n int) (int, int, int, bool) { return n, n, n, true }) {
+        break
+    }
+}
+
+func TestRepeat(t *testing.T) {
+    actual := []string{}
+    for v := range Repeat("a", 3) {
+        actual = append(actual, v)
+    }
+    expect := []string{"a", "a", "a"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    if c := Count(Repeat("a", 0)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+    if c := Count(Repeat("a", -1).Take(100)); c != 100 {
+        t.Fatal(fmt.Sprintf("expect: 100, actual: %d", c))
+    }
+}
+
+func TestCycle(t *testing.T) {
+    traversed := 0
+    source := func(yield func(int) bool) {
+        traversed++
+        for _, v := range []int{1, 2, 3} {
+            if !yield(v) {
+                return
+            }
+        }
+    }
+
+    actual := []int{}
+    for v := range Cycle(source).Take(8) {
+        actual = append(actual, v)
+    }
+    expect := []int{1, 2, 3, 1, 2, 3, 1, 2}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    if traversed != 1 {
+        t.Fatal(fmt.Sprintf("expect the source to be traversed once, actual: %d", traversed))
+    }
+
+    // breaking out of the loop during the first pass does not keep the values yielded so far
+    traversed = 0
+    cycle := Cycle(source)
+    for _ = range cycle.Take(2) {
+    }
+    actual = []int{}
+    for v := range cycle.Take(4) {
+        actual = append(actual, v)
+    }
+    expect = []int{1, 2, 3, 1}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    if traversed != 2 {
+        t.Fatal(fmt.Sprintf("expect the source to be traversed twice, actual: %d", traversed))
+    }
+    // so an iterator that cannot be traversed twice yields nothing afterward
+    cycle = Cycle(Once(Items(1, 2, 3)))
+    for _ = range cycle.Take(2) {
+    }
+    if c := Count(cycle.Take(5)); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+
+    if c := Count(Cycle(Empty[int]())); c != 0 {
+        t.Fatal(fmt.Sprintf("expect: 0, actual: %d", c))
+    }
+}
@@ -0,0 +1,161 @@
+// Package sqliter provides iterators over database/sql query results.
+package sqliter
+
+import (
+    "database/sql"
+    "fmt"
+    "reflect"
+    "strings"
+
+    "github.com/hsldymq/goiter"
+)
+
+// Rows returns an iterator that yields the values built by scan from each row of rows.
+// Since scanning and reading rows may fail, it yields 2-tuples of (value, error),
+// when an error occurs, it yields the zero value along with the error and then stops.
+// rows is always closed when the iteration completes, fails, or when you break out of the loop,
+// and as rows can only be read once, the returned iterator can only be iterated over once as well.
+// For example:
+//
+//  rows, err := db.QueryContext(ctx, "SELECT id, name FROM users")
+//  if err != nil {
+//      return err
+//  }
+//  users := sqliter.Rows(rows, func(r *sql.Rows) (User, error) {
+//      var u User
+//      err := r.Scan(&u.ID, &u.Name)
+//      return u, err
+//  })
+//  for u, err := range users {
+//      if err != nil {
+//          return err
+//      }
+//      fmt.Println(u.Name)
+//  }
+func Rows[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error)) goiter.Iterator2[T, error] {
+    return func(yield func(T, error) bool) {
+        var zero T
+        defer rows.Close()
+
+        for rows.Next() {
+            v, err := scan(rows)
+            if err != nil {
+                yield(zero, err)
+                return
+            }
+            if !yield(v, nil) {
+                return
+            }
+        }
+        if err := rows.Err(); err != nil {
+            yield(zero, err)
+        }
+    }
+}
+
+// StructRows is like Rows, but each row is scanned into a new struct of type T, columns are mapped to struct fields by name.
+// A field matches the column that has the same name as its "db" tag, or its own name ignoring case if it has no "db" tag,
+// fields tagged with `db:"-"` and unexported fields are ignored. It is an error if a column does not match any field.
+// Fields promoted from embedded structs are mapped as well, nil embedded pointers are allocated when one of their fields is scanned into,
+// but the fields promoted through an embedded pointer to an unexported struct type are ignored, since the pointer cannot be set.
+// For example:
+//
+//  type User struct {
+//      ID       int64  `db:"id"`
+//      FullName string `db:"full_name"`
+//      Email    string // matches the "email" column
+//  }
+//  rows, err := db.QueryContext(ctx, "SELECT id, full_name, email FROM users")
+//  ...
+//  for u, err := range sqliter.StructRows[User](rows) {
+//      ...
+//  }
+func StructRows[T any](rows *sql.Rows) goiter.Iterator2[T, error] {
+    var fieldIndexes [][]int
+    return Rows(rows, func(r *sql.Rows) (T, error) {
+        var v T
+        if fieldIndexes == nil {
+            columns, err := r.Columns()
+            if err != nil {
+                return v, err
+            }
+            fieldIndexes, err = mapColumns(reflect.TypeOf(v), columns)
+            if err != nil {
+                return v, err
+            }
+        }
+
+        rv := reflect.ValueOf(&v).Elem()
+        dest := make([]any, len(fieldIndexes))
+        for i, index := range fieldIndexes {
+            dest[i] = fieldByIndex(rv, index).Addr().Interface()
+        }
+        err := r.Scan(dest...)
+        return v, err
+    })
+}
+
+func mapColumns(t reflect.Type, columns []string) ([][]int, error) {
+    if t == nil || t.Kind() != reflect.Struct {
+        return nil, fmt.Errorf("sqliter: StructRows requires a struct type, got %v", t)
+    }
+
+    byTag := map[string][]int{}
+    byName := map[string][]int{}
+    for _, f := range reflect.VisibleFields(t) {
+        if !f.IsExported() || f.Anonymous || !settable(t, f.Index) {
+            continue
+        }
+        tag, hasTag := f.Tag.Lookup("db")
+        if tag == "-" {
+            continue
+        }
+        if hasTag && tag != "" {
+            byTag[tag] = f.Index
+        } else {
+            byName[strings.ToLower(f.Name)] = f.Index
+        }
+    }
+
+    indexes := make([][]int, len(columns))
+    for i, col := range columns {
+        if index, ok := byTag[col]; ok {
+            indexes[i] = index
+        } else if index, ok := byName[strings.ToLower(col)]; ok {
+            indexes[i] = index
+        } else {
+            return nil, fmt.Errorf("sqliter: column %q has no matching field in %v", col, t)
+        }
+    }
+    return indexes, nil
+}
+
+// settable reports whether the field at index can be set on a zero value of t,
+// that is, it is not promoted through an embedded pointer that is unexported.
+func settable(t reflect.Type, index []int) bool {
+    for _, x := range index[:len(index)-1] {
+        if t.Kind() == reflect.Pointer {
+            t = t.Elem()
+        }
+        f := t.Field(x)
+        if f.Type.Kind() == reflect.Pointer && !f.IsExported() {
+            return false
+        }
+        t = f.Type
+    }
+    return true
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but it allocates the nil embedded pointers on the way instead of panicking.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+    for i, x := range index {
+        if i > 0 && v.Kind() == reflect.Pointer {
+            if v.IsNil() {
+                v.Set(reflect.New(v.Type().Elem()))
+            }
+            v = v.Elem()
+        }
+        v = v.Field(x)
+    }
+    return v
+}
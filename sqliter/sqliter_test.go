@@ -0,0 +1,304 @@
+package sqliter
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "fmt"
+    "io"
+    "slices"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+)
+
+func TestRows(t *testing.T) {
+    db := openTestDB(t)
+
+    rows := query(t, db, "users")
+    actual := []string{}
+    for name, err := range Rows(rows, func(r *sql.Rows) (string, error) {
+        var id int64
+        var name, email string
+        err := r.Scan(&id, &name, &email)
+        return fmt.Sprintf("%d:%s", id, name), err
+    }) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, name)
+    }
+    expect := []string{"1:alice", "2:bob", "3:eve"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    assertClosed(t, rows)
+
+    // rows are closed when breaking out of the loop
+    rows = query(t, db, "users")
+    for _, _ = range Rows(rows, scanID) {
+        break
+    }
+    assertClosed(t, rows)
+
+    // scan error
+    rows = query(t, db, "users")
+    errScan := errors.New("scan failed")
+    count := 0
+    for _, err := range Rows(rows, func(r *sql.Rows) (int64, error) { return 0, errScan }) {
+        count++
+        if !errors.Is(err, errScan) {
+            t.Fatal("expect error:", errScan, "actual:", err)
+        }
+    }
+    if count != 1 {
+        t.Fatal(fmt.Sprintf("expect: 1, actual: %d", count))
+    }
+    assertClosed(t, rows)
+
+    // error while reading rows
+    rows = query(t, db, "broken")
+    actualIDs := []int64{}
+    var lastErr error
+    for id, err := range Rows(rows, scanID) {
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        actualIDs = append(actualIDs, id)
+    }
+    if !slices.Equal([]int64{1}, actualIDs) || !errors.Is(lastErr, errTestBroken) {
+        t.Fatal(fmt.Sprintf("expect: [1] %v, actual: %v %v", errTestBroken, actualIDs, lastErr))
+    }
+}
+
+func TestStructRows(t *testing.T) {
+    type base struct {
+        ID int64 `db:"id"`
+    }
+    type user struct {
+        base
+        FullName string `db:"name"`
+        Email    string
+        Ignored  string `db:"-"`
+        internal string
+    }
+    db := openTestDB(t)
+
+    rows := query(t, db, "users")
+    actual := []user{}
+    for u, err := range StructRows[user](rows) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, u)
+    }
+    expect := []user{
+        {base: base{1}, FullName: "alice", Email: "alice@example.com"},
+        {base: base{2}, FullName: "bob", Email: "bob@example.com"},
+        {base: base{3}, FullName: "eve", Email: "eve@example.com"},
+    }
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    assertClosed(t, rows)
+
+    // fields promoted through an embedded pointer
+    type Base struct {
+        ID   int64 `db:"id"`
+        Name string
+    }
+    type ptrUser struct {
+        *Base
+        Email string
+    }
+    rows = query(t, db, "users")
+    names := []string{}
+    for u, err := range StructRows[ptrUser](rows) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        if u.Base == nil {
+            t.Fatal("expect the embedded pointer to be allocated")
+        }
+        names = append(names, fmt.Sprintf("%d:%s:%s", u.ID, u.Name, u.Email))
+    }
+    expectNames := []string{"1:alice:alice@example.com", "2:bob:bob@example.com", "3:eve:eve@example.com"}
+    if !slices.Equal(expectNames, names) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectNames, names))
+    }
+    assertClosed(t, rows)
+
+    // the fields promoted through an embedded pointer to an unexported type are ignored
+    type unexportedPtr struct {
+        *base
+        FullName string `db:"name"`
+        Email    string
+    }
+    rows = query(t, db, "users")
+    for _, err := range StructRows[unexportedPtr](rows) {
+        if err == nil || !strings.Contains(err.Error(), `column "id" has no matching field`) {
+            t.Fatal("unexpected error:", err)
+        }
+    }
+    assertClosed(t, rows)
+
+    // unmatched column
+    type partial struct {
+        ID int64 `db:"id"`
+    }
+    rows = query(t, db, "users")
+    for _, err := range StructRows[partial](rows) {
+        if err == nil || !strings.Contains(err.Error(), `column "name" has no matching field`) {
+            t.Fatal("unexpected error:", err)
+        }
+    }
+    assertClosed(t, rows)
+
+    // not a struct
+    rows = query(t, db, "users")
+    for _, err := range StructRows[int](rows) {
+        if err == nil || !strings.Contains(err.Error(), "requires a struct type") {
+            t.Fatal("unexpected error:", err)
+        }
+    }
+    assertClosed(t, rows)
+}
+
+func scanID(r *sql.Rows) (int64, error) {
+    var id int64
+    var name, email string
+    err := r.Scan(&id, &name, &email)
+    return id, err
+}
+
+func query(t *testing.T, db *sql.DB, table string) *sql.Rows {
+    rows, err := db.QueryContext(context.Background(), table)
+    if err != nil {
+        t.Fatal("unexpected error:", err)
+    }
+    return rows
+}
+
+func assertClosed(t *testing.T, rows *sql.Rows) {
+    // Next returns false and Err returns nil on closed rows, and Scan reports that the rows are closed.
+    if err := rows.Scan(); err == nil || !strings.Contains(err.Error(), "closed") {
+        t.Fatal("expect rows to be closed, actual:", err)
+    }
+}
+
+var (
+    registerOnce  sync.Once
+    testDBCounter int32
+    errTestBroken = errors.New("connection broken")
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+    registerOnce.Do(func() {
+        sql.Register("sqliter_test", testDriver{})
+    })
+    db, err := sql.Open("sqliter_test", fmt.Sprintf("db%d", atomic.AddInt32(&testDBCounter, 1)))
+    if err != nil {
+        t.Fatal("unexpected error:", err)
+    }
+    t.Cleanup(func() { _ = db.Close() })
+    return db
+}
+
+// testDriver is a minimal in-memory driver, the query string is the name of a table in testTables.
+type testDriver struct{}
+
+func (testDriver) Open(name string) (driver.Conn, error) {
+    return testConn{}, nil
+}
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) {
+    return testStmt{table: query}, nil
+}
+
+func (testConn) Close() error {
+    return nil
+}
+
+func (testConn) Begin() (driver.Tx, error) {
+    return nil, errors.New("transactions are not supported")
+}
+
+type testStmt struct {
+    table string
+}
+
+func (s testStmt) Close() error {
+    return nil
+}
+
+func (s testStmt) NumInput() int {
+    return 0
+}
+
+func (s testStmt) Exec(args []driver.Value) (driver.Result, error) {
+    return nil, errors.New("exec is not supported")
+}
+
+func (s testStmt) Query(args []driver.Value) (driver.Rows, error) {
+    data, ok := testTables[s.table]
+    if !ok {
+        return nil, fmt.Errorf("no such table: %s", s.table)
+    }
+    return &testRows{data: data}, nil
+}
+
+type testTable struct {
+    columns []string
+    rows    [][]driver.Value
+    // failAt makes reading the row at this index fail, it is disabled if it is 0.
+    failAt int
+}
+
+var testTables = map[string]testTable{
+    "users": {
+        columns: []string{"id", "name", "email"},
+        rows: [][]driver.Value{
+            {int64(1), "alice", "alice@example.com"},
+            {int64(2), "bob", "bob@example.com"},
+            {int64(3), "eve", "eve@example.com"},
+        },
+    },
+    "broken": {
+        columns: []string{"id", "name", "email"},
+        rows: [][]driver.Value{
+            {int64(1), "alice", "alice@example.com"},
+            {int64(2), "bob", "bob@example.com"},
+        },
+        failAt: 1,
+    },
+}
+
+type testRows struct {
+    data testTable
+    pos  int
+}
+
+func (r *testRows) Columns() []string {
+    return r.data.columns
+}
+
+func (r *testRows) Close() error {
+    return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+    if r.data.failAt > 0 && r.pos == r.data.failAt {
+        return errTestBroken
+    }
+    if r.pos >= len(r.data.rows) {
+        return io.EOF
+    }
+    copy(dest, r.data.rows[r.pos])
+    r.pos++
+    return nil
+}
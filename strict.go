@@ -0,0 +1,156 @@
+package goiter
+
+import (
+    "fmt"
+    "runtime"
+    "strconv"
+    "strings"
+    "sync/atomic"
+)
+
+// ViolationKind describes how an iterator breaks the contract of range-over-func iterators.
+type ViolationKind int
+
+const (
+    // YieldAfterFalse means yield was called again after it had returned false.
+    YieldAfterFalse ViolationKind = iota
+    // YieldAfterReturn means yield was called after the iterator function had returned, e.g. from a leftover goroutine.
+    YieldAfterReturn
+    // ConcurrentYield means yield was called from another goroutine while a previous call had not returned yet.
+    ConcurrentYield
+    // ReentrantYield means yield was called again by the same goroutine while a previous call had not returned yet.
+    ReentrantYield
+)
+
+func (k ViolationKind) String() string {
+    switch k {
+    case YieldAfterFalse:
+        return "yield called after it returned false"
+    case YieldAfterReturn:
+        return "yield called after the iterator returned"
+    case ConcurrentYield:
+        return "yield called concurrently"
+    case ReentrantYield:
+        return "yield called re-entrantly"
+    }
+    return "unknown violation"
+}
+
+// ContractViolation is the value that Strict and Strict2 panic with when the iterator breaks the contract.
+type ContractViolation struct {
+    Kind ViolationKind
+    // Location is the file and line where yield was called in violation of the contract.
+    Location string
+}
+
+func (v *ContractViolation) Error() string {
+    return fmt.Sprintf("goiter: iterator contract violation: %s at %s", v.Kind, v.Location)
+}
+
+// Strict returns an iterator that yields the same values as the input iterator, but checks that the input iterator respects the contract of range-over-func iterators:
+// yield must not be called again after it returns false, nor after the iterator function returns, and calls of yield must not overlap, either concurrently or re-entrantly.
+// When the contract is broken, it panics with a *ContractViolation that reports the location where yield was called,
+// rather than leaving the runtime to panic somewhere far from the bug, or not at all.
+// It is meant to be used in tests of your own iterators, because the checks slow down each iteration considerably.
+// For example:
+//
+//  func TestMyIterator(t *testing.T) {
+//      for v := range goiter.Strict(MyIterator()) {
+//          ...
+//      }
+//  }
+func Strict[TIter SeqX[T], T any](iterator TIter) Iterator[T] {
+    return func(yield func(T) bool) {
+        c := &strictChecker{}
+        defer c.finish()
+        iterator(func(v T) bool {
+            c.enter()
+            ok := yield(v)
+            c.exit(ok)
+            return ok
+        })
+    }
+}
+
+// Strict2 is the iter.Seq2 version of Strict function.
+func Strict2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter) Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        c := &strictChecker{}
+        defer c.finish()
+        iterator(func(v1 T1, v2 T2) bool {
+            c.enter()
+            ok := yield(v1, v2)
+            c.exit(ok)
+            return ok
+        })
+    }
+}
+
+const (
+    strictIdle int32 = iota
+    strictInYield
+    strictStopped
+    strictReturned
+)
+
+type strictChecker struct {
+    state atomic.Int32
+    // gid is the id of the goroutine that is running yield
+    gid atomic.Uint64
+}
+
+// enter must be called directly by the yield wrapper, so that the caller of the wrapper is reported.
+func (c *strictChecker) enter() {
+    for {
+        switch c.state.Load() {
+        case strictStopped:
+            panic(newContractViolation(YieldAfterFalse))
+        case strictReturned:
+            panic(newContractViolation(YieldAfterReturn))
+        case strictInYield:
+            if c.gid.Load() == goroutineID() {
+                panic(newContractViolation(ReentrantYield))
+            }
+            panic(newContractViolation(ConcurrentYield))
+        case strictIdle:
+            if c.state.CompareAndSwap(strictIdle, strictInYield) {
+                c.gid.Store(goroutineID())
+                return
+            }
+        }
+    }
+}
+
+func (c *strictChecker) exit(ok bool) {
+    c.gid.Store(0)
+    if ok {
+        c.state.Store(strictIdle)
+    } else {
+        c.state.Store(strictStopped)
+    }
+}
+
+func (c *strictChecker) finish() {
+    c.state.Store(strictReturned)
+}
+
+func newContractViolation(kind ViolationKind) *ContractViolation {
+    // skip newContractViolation, enter and the yield wrapper
+    location := "unknown location"
+    if _, file, line, ok := runtime.Caller(3); ok {
+        location = file + ":" + strconv.Itoa(line)
+    }
+    return &ContractViolation{Kind: kind, Location: location}
+}
+
+func goroutineID() uint64 {
+    var buf [64]byte
+    n := runtime.Stack(buf[:], false)
+    // the first line looks like "goroutine 123 [running]:"
+    s := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+    if i := strings.IndexByte(s, ' '); i >= 0 {
+        s = s[:i]
+    }
+    id, _ := strconv.ParseUint(s, 10, 64)
+    return id
+}
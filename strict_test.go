@@ -0,0 +1,154 @@
+package goiter
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+    "sync"
+    "testing"
+)
+
+func TestStrict(t *testing.T) {
+    // a well-behaved iterator passes through untouched
+    actual := []int{}
+    for v := range Strict(Items(1, 2, 3)) {
+        actual = append(actual, v)
+    }
+    expect := []int{1, 2, 3}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    for v := range Strict(Items(1, 2, 3)) {
+        if v == 2 {
+            break
+        }
+    }
+
+    // ignores the result of yield
+    ignoring := Iterator[int](func(yield func(int) bool) {
+        yield(1)
+        yield(2)
+    })
+    v := expectViolation(t, func() {
+        for _ = range Strict(ignoring) {
+            break
+        }
+    })
+    if v.Kind != YieldAfterFalse {
+        t.Fatal("unexpected violation:", v)
+    }
+    if !strings.Contains(v.Location, "strict_test.go") {
+        t.Fatal("expect the location of the bad yield, actual:", v.Location)
+    }
+
+    // keeps the yield function and calls it later
+    var saved func(int) bool
+    leaking := Iterator[int](func(yield func(int) bool) {
+        saved = yield
+    })
+    v = expectViolation(t, func() {
+        for _ = range Strict(leaking) {
+        }
+        saved(1)
+    })
+    if v.Kind != YieldAfterReturn {
+        t.Fatal("unexpected violation:", v)
+    }
+
+    // calls yield from within yield
+    var reentrant func(int) bool
+    reentering := Iterator[int](func(yield func(int) bool) {
+        reentrant = yield
+        yield(1)
+    })
+    v = expectViolation(t, func() {
+        for v := range Strict(reentering) {
+            if v == 1 {
+                reentrant(2)
+            }
+        }
+    })
+    if v.Kind != ReentrantYield {
+        t.Fatal("unexpected violation:", v)
+    }
+
+    // calls yield from another goroutine while yield is running
+    inYield := make(chan struct{})
+    result := make(chan any)
+    concurrent := Iterator[int](func(yield func(int) bool) {
+        go func() {
+            defer func() { result <- recover() }()
+            <-inYield
+            yield(2)
+        }()
+        yield(1)
+    })
+    for _ = range Strict(concurrent) {
+        close(inYield)
+        p := <-result
+        if cv, ok := p.(*ContractViolation); !ok || cv.Kind != ConcurrentYield {
+            t.Fatal("unexpected violation:", p)
+        }
+    }
+}
+
+func TestStrict2(t *testing.T) {
+    actual := []string{}
+    for i, v := range Strict2(Slice([]string{"a", "b"})) {
+        actual = append(actual, fmt.Sprintf("%d%s", i, v))
+    }
+    expect := []string{"0a", "1b"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    ignoring := Iterator2[string, int](func(yield func(string, int) bool) {
+        yield("a", 1)
+        yield("b", 2)
+    })
+    v := expectViolation(t, func() {
+        for _, _ = range Strict2(ignoring) {
+            break
+        }
+    })
+    if v.Kind != YieldAfterFalse {
+        t.Fatal("unexpected violation:", v)
+    }
+
+    // concurrent yields are fine as long as they do not overlap
+    var mu sync.Mutex
+    serialized := Iterator2[int, int](func(yield func(int, int) bool) {
+        var wg sync.WaitGroup
+        for i := range 10 {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                mu.Lock()
+                defer mu.Unlock()
+                yield(i, i)
+            }()
+        }
+        wg.Wait()
+    })
+    count := 0
+    for _, _ = range Strict2(serialized) {
+        count++
+    }
+    if count != 10 {
+        t.Fatal(fmt.Sprintf("expect: 10, actual: %d", count))
+    }
+}
+
+func expectViolation(t *testing.T, f func()) (violation *ContractViolation) {
+    t.Helper()
+    defer func() {
+        p := recover()
+        err, _ := p.(error)
+        if !errors.As(err, &violation) {
+            t.Fatal("expect a ContractViolation, actual:", p)
+        }
+    }()
+    f()
+    return nil
+}
@@ -0,0 +1,146 @@
+package goiter
+
+import (
+    "context"
+    "log/slog"
+    "time"
+)
+
+// Tap returns an iterator that yields the same values as the input iterator, and calls f with each value before yielding it.
+// It is useful for side effects such as logging or collecting metrics in the middle of a chain of operators.
+// For example:
+//
+//  iterator := goiter.Range(1, 10).
+//      Filter(func(v int) bool { return v%2 == 0 }).
+//      Tap(func(v int) { fmt.Println("passed the filter:", v) }).
+//      Take(2)
+//  for v := range iterator {   // prints "passed the filter: 2" and "passed the filter: 4"
+//      ...
+//  }
+func Tap[TIter SeqX[T], T any](iterator TIter, f func(T)) Iterator[T] {
+    return func(yield func(T) bool) {
+        for v := range iterator {
+            f(v)
+            if !yield(v) {
+                return
+            }
+        }
+    }
+}
+
+// Tap2 is the iter.Seq2 version of Tap function.
+func Tap2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter, f func(T1, T2)) Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        for v1, v2 := range iterator {
+            f(v1, v2)
+            if !yield(v1, v2) {
+                return
+            }
+        }
+    }
+}
+
+// Trace returns an iterator that yields the same values as the input iterator, and logs the iteration to logger at debug level,
+// so you can see what flows through each step of a chain of operators.
+// For each value, it logs the name, the index, the value and the time elapsed since the previous value, or since the start for the first value,
+// and once the iteration is over, it logs the number of values and the reason, which is one of the DoneReason values.
+// The value is logged as is, unless format is given, in which case the result of format is logged instead.
+// If logger is nil, slog.Default() is used. Nothing is computed if the logger does not enable debug level.
+// For example:
+//
+//  logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//  iterator := goiter.Trace(goiter.Range(1, 10), logger, "source").
+//      Filter(func(v int) bool { return v%2 == 0 }).
+//      Trace(logger, "filtered").
+//      Take(2)
+//  for v := range iterator {
+//      ...
+//  }
+//  // level=DEBUG msg="goiter: yield" name=source index=0 value=1 elapsed=1.2µs
+//  // level=DEBUG msg="goiter: yield" name=source index=1 value=2 elapsed=800ns
+//  // level=DEBUG msg="goiter: yield" name=filtered index=0 value=2 elapsed=3.1µs
+//  // ...
+//  // level=DEBUG msg="goiter: done" name=source count=4 reason=broken
+//  // level=DEBUG msg="goiter: done" name=filtered count=2 reason=broken
+func Trace[TIter SeqX[T], T any](iterator TIter, logger *slog.Logger, name string, format ...func(T) any) Iterator[T] {
+    return func(yield func(T) bool) {
+        t := newTracer(logger, name)
+        traced := Tap(iterator, func(v T) {
+            if t.enabled() {
+                var value any = v
+                if len(format) > 0 {
+                    value = format[0](v)
+                }
+                t.yield(value)
+            }
+            t.index++
+        })
+        OnDone(traced, t.done)(yield)
+    }
+}
+
+// Trace2 is the iter.Seq2 version of Trace function, it logs the 2-tuples as two values named v1 and v2, unless format is given.
+func Trace2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter, logger *slog.Logger, name string, format ...func(T1, T2) any) Iterator2[T1, T2] {
+    return func(yield func(T1, T2) bool) {
+        t := newTracer(logger, name)
+        traced := Tap2(iterator, func(v1 T1, v2 T2) {
+            if t.enabled() {
+                if len(format) > 0 {
+                    t.yield(format[0](v1, v2))
+                } else {
+                    t.yield2(v1, v2)
+                }
+            }
+            t.index++
+        })
+        OnDone2(traced, t.done)(yield)
+    }
+}
+
+type tracer struct {
+    logger *slog.Logger
+    name   string
+    index  int
+    last   time.Time
+}
+
+func newTracer(logger *slog.Logger, name string) *tracer {
+    if logger == nil {
+        logger = slog.Default()
+    }
+    return &tracer{
+        logger: logger,
+        name:   name,
+        last:   time.Now(),
+    }
+}
+
+func (t *tracer) enabled() bool {
+    return t.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func (t *tracer) yield(value any) {
+    t.logYield(slog.Any("value", value))
+}
+
+func (t *tracer) yield2(v1, v2 any) {
+    t.logYield(slog.Any("v1", v1), slog.Any("v2", v2))
+}
+
+func (t *tracer) logYield(values ...slog.Attr) {
+    now := time.Now()
+    attrs := make([]slog.Attr, 0, len(values)+3)
+    attrs = append(attrs, slog.String("name", t.name), slog.Int("index", t.index))
+    attrs = append(attrs, values...)
+    attrs = append(attrs, slog.Duration("elapsed", now.Sub(t.last)))
+    t.logger.LogAttrs(context.Background(), slog.LevelDebug, "goiter: yield", attrs...)
+    t.last = now
+}
+
+func (t *tracer) done(reason DoneReason) {
+    t.logger.LogAttrs(context.Background(), slog.LevelDebug, "goiter: done",
+        slog.String("name", t.name),
+        slog.Int("count", t.index),
+        slog.String("reason", reason.String()),
+    )
+}
@@ -0,0 +1,142 @@
+package goiter
+
+import (
+    "bytes"
+    "fmt"
+    "log/slog"
+    "slices"
+    "strings"
+    "testing"
+)
+
+func TestTap(t *testing.T) {
+    tapped := []int{}
+    actual := []int{}
+    iterator := Range(1, 10).
+        Filter(func(v int) bool { return v%2 == 0 }).
+        Tap(func(v int) { tapped = append(tapped, v) }).
+        Take(2)
+    for v := range iterator {
+        actual = append(actual, v)
+    }
+    expect := []int{2, 4}
+    if !slices.Equal(expect, actual) || !slices.Equal(expect, tapped) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v %v", expect, actual, tapped))
+    }
+}
+
+func TestTap2(t *testing.T) {
+    tapped := []string{}
+    for _, _ = range Slice([]string{"a", "b", "c"}).Tap(func(i int, v string) {
+        tapped = append(tapped, fmt.Sprintf("%d%s", i, v))
+    }) {
+    }
+    expect := []string{"0a", "1b", "2c"}
+    if !slices.Equal(expect, tapped) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, tapped))
+    }
+}
+
+func TestTrace(t *testing.T) {
+    buf := &bytes.Buffer{}
+    logger := newTestLogger(buf, slog.LevelDebug)
+    iterator := Trace(Range(1, 10), logger, "source").
+        Filter(func(v int) bool { return v%2 == 0 }).
+        Trace(logger, "filtered", func(v int) any { return fmt.Sprintf("#%d", v) }).
+        Take(2)
+    actual := []int{}
+    for v := range iterator {
+        actual = append(actual, v)
+    }
+    if !slices.Equal([]int{2, 4}, actual) {
+        t.Fatal(fmt.Sprintf("expect: [2 4], actual: %v", actual))
+    }
+
+    expect := []string{
+        `msg="goiter: yield" name=source index=0 value=1`,
+        `msg="goiter: yield" name=source index=1 value=2`,
+        `msg="goiter: yield" name=filtered index=0 value=#2`,
+        `msg="goiter: yield" name=source index=2 value=3`,
+        `msg="goiter: yield" name=source index=3 value=4`,
+        `msg="goiter: yield" name=filtered index=1 value=#4`,
+        `msg="goiter: done" name=source count=4 reason=broken`,
+        `msg="goiter: done" name=filtered count=2 reason=broken`,
+    }
+    assertLogLines(t, expect, buf.String())
+
+    // exhausted
+    buf.Reset()
+    for _ = range Trace(Items(1), logger, "items") {
+    }
+    assertLogLines(t, []string{
+        `msg="goiter: yield" name=items index=0 value=1`,
+        `msg="goiter: done" name=items count=1 reason=exhausted`,
+    }, buf.String())
+
+    // panicked
+    buf.Reset()
+    func() {
+        defer func() { _ = recover() }()
+        for _ = range Trace(Items(1, 2), logger, "items") {
+            panic("body")
+        }
+    }()
+    assertLogLines(t, []string{
+        `msg="goiter: yield" name=items index=0 value=1`,
+        `msg="goiter: done" name=items count=1 reason=panicked`,
+    }, buf.String())
+
+    // debug level disabled
+    buf.Reset()
+    for _ = range Trace(Items(1, 2), newTestLogger(buf, slog.LevelInfo), "items") {
+    }
+    if buf.Len() != 0 {
+        t.Fatal("expect nothing to be logged, actual:", buf.String())
+    }
+}
+
+func TestTrace2(t *testing.T) {
+    buf := &bytes.Buffer{}
+    logger := newTestLogger(buf, slog.LevelDebug)
+    for _, _ = range Slice([]string{"a", "b"}).Trace(logger, "slice") {
+    }
+    for _, _ = range Trace2(Slice([]string{"c"}), logger, "formatted", func(i int, v string) any { return fmt.Sprintf("%d:%s", i, v) }) {
+    }
+    assertLogLines(t, []string{
+        `msg="goiter: yield" name=slice index=0 v1=0 v2=a`,
+        `msg="goiter: yield" name=slice index=1 v1=1 v2=b`,
+        `msg="goiter: done" name=slice count=2 reason=exhausted`,
+        `msg="goiter: yield" name=formatted index=0 value=0:c`,
+        `msg="goiter: done" name=formatted count=1 reason=exhausted`,
+    }, buf.String())
+}
+
+func newTestLogger(buf *bytes.Buffer, level slog.Level) *slog.Logger {
+    return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+        Level: level,
+        ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+            // drop the time, which varies between runs, and the level, which is always DEBUG
+            if a.Key == slog.TimeKey || a.Key == slog.LevelKey {
+                return slog.Attr{}
+            }
+            return a
+        },
+    }))
+}
+
+// assertLogLines checks that each line of the log starts with the expected text, the elapsed time at the end of a yield line is ignored.
+func assertLogLines(t *testing.T, expect []string, log string) {
+    t.Helper()
+    lines := strings.Split(strings.TrimSpace(log), "\n")
+    if len(lines) != len(expect) {
+        t.Fatal(fmt.Sprintf("expect %d lines, actual:\n%s", len(expect), log))
+    }
+    for i, line := range lines {
+        if !strings.HasPrefix(line, expect[i]) {
+            t.Fatal(fmt.Sprintf("expect line %d to start with %s, actual: %s", i, expect[i], line))
+        }
+        if strings.Contains(expect[i], "goiter: yield") && !strings.Contains(line, " elapsed=") {
+            t.Fatal(fmt.Sprintf("expect line %d to contain the elapsed time, actual: %s", i, line))
+        }
+    }
+}
@@ -0,0 +1,204 @@
+package goiter
+
+import (
+    "fmt"
+    "slices"
+)
+
+// CycleError is the error yielded by TopoSort and TopoLevels when the dependencies contain a cycle.
+type CycleError[N any] struct {
+    // Cycle is one of the cycles found, the first node is repeated at the end, e.g. [a b c a] means a depends on b, b depends on c and c depends on a.
+    Cycle []N
+    // Unresolved contains all the nodes that cannot be sorted, they are either in a cycle or depend on a node in a cycle.
+    Unresolved []N
+}
+
+func (e *CycleError[N]) Error() string {
+    return fmt.Sprintf("goiter: dependency cycle detected: %v", e.Cycle)
+}
+
+// TopoSort returns an iterator that yields nodes in dependency order, so every node is yielded after all the nodes it depends on.
+// deps returns the nodes that a node depends on, nodes found by deps are included in the result even if the input iterator does not yield them.
+// When more than one node is ready at the same time, the one that is less according to cmp comes first,
+// and if cmp is nil or reports them as equal, the one that was found first comes first, so the result is deterministic.
+//
+// It uses Kahn's algorithm, the graph is built when the iteration starts, and then nodes are yielded as soon as they are sorted.
+// The returned iterator yields 2-tuples of (node, error). If there is a cycle, after all the nodes that can be sorted have been yielded,
+// it yields the zero value along with a *CycleError and then stops.
+// For example:
+//
+//  deps := map[string][]string{"app": {"lib", "log"}, "lib": {"log"}}
+//  for task, err := range goiter.TopoSort(goiter.Items("app"), func(n string) goiter.Iterator[string] {
+//      return goiter.SliceElems(deps[n])
+//  }, strings.Compare) {
+//      if err != nil {
+//          return err
+//      }
+//      fmt.Println(task) // prints log lib app
+//  }
+func TopoSort[TIter SeqX[N], N comparable](
+    nodes TIter,
+    deps func(N) Iterator[N],
+    cmp func(N, N) int,
+) Iterator2[N, error] {
+    return func(yield func(N, error) bool) {
+        var zero N
+        g := buildTopoGraph(nodes, deps, cmp)
+        ready := &kHeap[N]{cmp: g.compare}
+        for _, n := range g.order {
+            if g.indegree[n] == 0 {
+                ready.push(n)
+            }
+        }
+
+        sorted := 0
+        for len(ready.items) > 0 {
+            n := ready.pop()
+            sorted++
+            if !yield(n, nil) {
+                return
+            }
+            for _, d := range g.dependents[n] {
+                g.indegree[d]--
+                if g.indegree[d] == 0 {
+                    ready.push(d)
+                }
+            }
+        }
+        if sorted < len(g.order) {
+            yield(zero, g.cycleError())
+        }
+    }
+}
+
+// TopoLevels is like TopoSort, but it yields batches of nodes, every node in a batch only depends on nodes in previous batches,
+// so nodes in the same batch can be processed in parallel. Nodes in a batch are ordered by cmp in the same way as TopoSort.
+// If there is a cycle, after all the batches that can be sorted have been yielded, it yields nil along with a *CycleError and then stops.
+// For example, with the same deps as the example of TopoSort, TopoLevels will yield [log] [lib] [app].
+func TopoLevels[TIter SeqX[N], N comparable](
+    nodes TIter,
+    deps func(N) Iterator[N],
+    cmp func(N, N) int,
+) Iterator2[[]N, error] {
+    return func(yield func([]N, error) bool) {
+        g := buildTopoGraph(nodes, deps, cmp)
+        level := make([]N, 0)
+        for _, n := range g.order {
+            if g.indegree[n] == 0 {
+                level = append(level, n)
+            }
+        }
+
+        sorted := 0
+        for len(level) > 0 {
+            slices.SortStableFunc(level, g.compare)
+            sorted += len(level)
+            next := make([]N, 0)
+            for _, n := range level {
+                for _, d := range g.dependents[n] {
+                    g.indegree[d]--
+                    if g.indegree[d] == 0 {
+                        next = append(next, d)
+                    }
+                }
+            }
+            if !yield(level, nil) {
+                return
+            }
+            level = next
+        }
+        if sorted < len(g.order) {
+            yield(nil, g.cycleError())
+        }
+    }
+}
+
+type topoGraph[N comparable] struct {
+    order      []N
+    index      map[N]int
+    deps       map[N][]N
+    dependents map[N][]N
+    indegree   map[N]int
+    cmp        func(N, N) int
+}
+
+func buildTopoGraph[TIter SeqX[N], N comparable](
+    nodes TIter,
+    deps func(N) Iterator[N],
+    cmp func(N, N) int,
+) *topoGraph[N] {
+    g := &topoGraph[N]{
+        order:      make([]N, 0),
+        index:      map[N]int{},
+        deps:       map[N][]N{},
+        dependents: map[N][]N{},
+        indegree:   map[N]int{},
+        cmp:        cmp,
+    }
+    add := func(n N) {
+        if _, ok := g.index[n]; !ok {
+            g.index[n] = len(g.order)
+            g.order = append(g.order, n)
+        }
+    }
+    for n := range nodes {
+        add(n)
+    }
+
+    // g.order grows while new nodes are found by deps
+    for i := 0; i < len(g.order); i++ {
+        n := g.order[i]
+        seen := map[N]bool{}
+        for d := range deps(n) {
+            if seen[d] {
+                continue
+            }
+            seen[d] = true
+            add(d)
+            g.deps[n] = append(g.deps[n], d)
+            g.dependents[d] = append(g.dependents[d], n)
+            g.indegree[n]++
+        }
+    }
+    return g
+}
+
+func (g *topoGraph[N]) compare(a, b N) int {
+    if g.cmp != nil {
+        if c := g.cmp(a, b); c != 0 {
+            return c
+        }
+    }
+    return g.index[a] - g.index[b]
+}
+
+// cycleError must be called after sorting, the nodes whose indegree is still positive are the unresolved ones.
+func (g *topoGraph[N]) cycleError() *CycleError[N] {
+    unresolved := make([]N, 0)
+    for _, n := range g.order {
+        if g.indegree[n] > 0 {
+            unresolved = append(unresolved, n)
+        }
+    }
+
+    // every unresolved node depends on at least one unresolved node, so following them must end up in a cycle.
+    pos := map[N]int{}
+    path := make([]N, 0)
+    curr := unresolved[0]
+    for {
+        if start, ok := pos[curr]; ok {
+            return &CycleError[N]{
+                Cycle:      append(path[start:], curr),
+                Unresolved: unresolved,
+            }
+        }
+        pos[curr] = len(path)
+        path = append(path, curr)
+        for _, d := range g.deps[curr] {
+            if g.indegree[d] > 0 {
+                curr = d
+                break
+            }
+        }
+    }
+}
@@ -0,0 +1,164 @@
+package goiter
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+    "testing"
+)
+
+func TestTopoSort(t *testing.T) {
+    graph := map[string][]string{
+        "app":    {"lib", "log", "config"},
+        "lib":    {"log", "util"},
+        "config": {"util"},
+        "log":    {"util"},
+    }
+    deps := func(n string) Iterator[string] {
+        return SliceElems(graph[n])
+    }
+
+    // case 1: sorted by name when more than one node is ready
+    actual := []string{}
+    for n, err := range TopoSort(Items("app"), deps, strings.Compare) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, n)
+    }
+    expect := []string{"util", "config", "log", "lib", "app"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    // case 2: without cmp, the order in which nodes are found is used
+    actual = []string{}
+    for n, err := range TopoSort(Items("app", "extra"), deps, nil) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, n)
+    }
+    expect = []string{"extra", "util", "log", "lib", "config", "app"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    // case 3: duplicated nodes and dependencies
+    actual = []string{}
+    for n, _ := range TopoSort(Items("b", "a", "b"), func(n string) Iterator[string] {
+        if n == "b" {
+            return Items("a", "a")
+        }
+        return Empty[string]()
+    }, nil) {
+        actual = append(actual, n)
+    }
+    expect = []string{"a", "b"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    for _, _ = range TopoSort(Items("app"), deps, nil) {
+        break
+    }
+}
+
+func TestTopoSort_Cycle(t *testing.T) {
+    graph := map[string][]string{
+        "app": {"a", "x"},
+        "a":   {"b"},
+        "b":   {"c"},
+        "c":   {"a"},
+    }
+    deps := func(n string) Iterator[string] {
+        return SliceElems(graph[n])
+    }
+
+    actual := []string{}
+    var cycleErr *CycleError[string]
+    for n, err := range TopoSort(Items("app"), deps, strings.Compare) {
+        if err != nil {
+            if !errors.As(err, &cycleErr) {
+                t.Fatal("expect a CycleError, actual:", err)
+            }
+            continue
+        }
+        actual = append(actual, n)
+    }
+    expect := []string{"x"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+    if cycleErr == nil {
+        t.Fatal("expect a CycleError")
+    }
+    expectCycle := []string{"a", "b", "c", "a"}
+    if !slices.Equal(expectCycle, cycleErr.Cycle) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectCycle, cycleErr.Cycle))
+    }
+    expectUnresolved := []string{"app", "a", "b", "c"}
+    if !slices.Equal(expectUnresolved, cycleErr.Unresolved) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expectUnresolved, cycleErr.Unresolved))
+    }
+    if cycleErr.Error() != "goiter: dependency cycle detected: [a b c a]" {
+        t.Fatal("unexpected error message:", cycleErr.Error())
+    }
+
+    // self dependency
+    for _, err := range TopoSort(Items(1), func(n int) Iterator[int] { return Items(n) }, nil) {
+        var selfErr *CycleError[int]
+        if !errors.As(err, &selfErr) || !slices.Equal([]int{1, 1}, selfErr.Cycle) {
+            t.Fatal("expect a self dependency cycle, actual:", err)
+        }
+    }
+}
+
+func TestTopoLevels(t *testing.T) {
+    graph := map[string][]string{
+        "app":    {"lib", "log", "config"},
+        "lib":    {"log", "util"},
+        "config": {"util"},
+        "log":    {"util"},
+        "test":   {"app"},
+    }
+    deps := func(n string) Iterator[string] {
+        return SliceElems(graph[n])
+    }
+
+    actual := []string{}
+    for level, err := range TopoLevels(Items("test", "docs"), deps, strings.Compare) {
+        if err != nil {
+            t.Fatal("unexpected error:", err)
+        }
+        actual = append(actual, strings.Join(level, ","))
+    }
+    expect := []string{"docs,util", "config,log", "lib", "app", "test"}
+    if !slices.Equal(expect, actual) {
+        t.Fatal(fmt.Sprintf("expect: %v, actual: %v", expect, actual))
+    }
+
+    graph["util"] = []string{"test"}
+    count := 0
+    for level, err := range TopoLevels(Items("test", "docs"), deps, strings.Compare) {
+        count++
+        if err == nil {
+            if !slices.Equal([]string{"docs"}, level) {
+                t.Fatal(fmt.Sprintf("expect: [docs], actual: %v", level))
+            }
+            continue
+        }
+        var cycleErr *CycleError[string]
+        if !errors.As(err, &cycleErr) || level != nil {
+            t.Fatal("expect a CycleError, actual:", err)
+        }
+    }
+    if count != 2 {
+        t.Fatal(fmt.Sprintf("expect: 2, actual: %d", count))
+    }
+
+    for _, _ = range TopoLevels(Items("test"), deps, nil) {
+        break
+    }
+}
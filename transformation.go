@@ -1,9 +1,5 @@
 package goiter
 
-import (
-    "iter"
-)
-
 // PickV1 returns an iterator that yields the first element of each 2-tuple provided by the input iterator.
 // For example:
 //  iterator := goiter.Slice([]string{"a", "b", "c"})       // iterator will yield (1, "a") (2, "b") (3, "c")
@@ -43,13 +39,7 @@ func Transform[TIter SeqX[T], TOut, T any](
     transformer func(T) TOut,
 ) Iterator[TOut] {
     return func(yield func(TOut) bool) {
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             out := transformer(v)
             if !yield(out) {
                 return
@@ -64,13 +54,7 @@ func Transform2[TIter Seq2X[T1, T2], TOut1, TOut2, T1, T2 any](
     transformer func(T1, T2) (TOut1, TOut2),
 ) Iterator2[TOut1, TOut2] {
     return func(yield func(TOut1, TOut2) bool) {
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             out1, out2 := transformer(v1, v2)
             if !yield(out1, out2) {
                 return
@@ -90,13 +74,7 @@ func Transform12[TIter SeqX[T], OutT1, OutT2, T any](
     transformer func(T) (OutT1, OutT2),
 ) Iterator2[OutT1, OutT2] {
     return func(yield func(OutT1, OutT2) bool) {
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-        for {
-            v, ok := next()
-            if !ok {
-                return
-            }
+        for v := range iterator {
             out1, out2 := transformer(v)
             if !yield(out1, out2) {
                 return
@@ -111,13 +89,7 @@ func Transform21[TIter Seq2X[T1, T2], TOut, T1, T2 any](
     transformer func(T1, T2) TOut,
 ) Iterator[TOut] {
     return func(yield func(TOut) bool) {
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                return
-            }
+        for v1, v2 := range iterator {
             out := transformer(v1, v2)
             if !yield(out) {
                 return
@@ -0,0 +1,150 @@
+package goiter
+
+// DFS returns an iterator that traverses a tree in depth-first pre-order, starting from root,
+// so each node is yielded before its children. children is called to get the child nodes of a node, and it is only called when the node is expanded.
+// The optional skip predicate prunes the tree, when it returns true for a node, neither the node nor its descendants are yielded.
+// DFS does not detect cycles, so use GraphDFS for structures that may contain cycles or shared nodes.
+// For example:
+//
+//  // walk through all the non-hidden entries of a directory-like structure
+//  entries := goiter.DFS(rootDir, func(e *Entry) goiter.Iterator[*Entry] {
+//      return goiter.SliceElems(e.Children)
+//  }, func(e *Entry) bool {
+//      return strings.HasPrefix(e.Name, ".")
+//  })
+func DFS[N any](root N, children func(N) Iterator[N], skip ...func(N) bool) Iterator[N] {
+    return func(yield func(N) bool) {
+        shouldSkip := traversalSkipper(skip)
+        var visit func(n N) bool
+        visit = func(n N) bool {
+            if shouldSkip(n) {
+                return true
+            }
+            if !yield(n) {
+                return false
+            }
+            for c := range children(n) {
+                if !visit(c) {
+                    return false
+                }
+            }
+            return true
+        }
+        visit(root)
+    }
+}
+
+// PostOrder is like DFS, but each node is yielded after all its descendants.
+func PostOrder[N any](root N, children func(N) Iterator[N], skip ...func(N) bool) Iterator[N] {
+    return func(yield func(N) bool) {
+        shouldSkip := traversalSkipper(skip)
+        var visit func(n N) bool
+        visit = func(n N) bool {
+            if shouldSkip(n) {
+                return true
+            }
+            for c := range children(n) {
+                if !visit(c) {
+                    return false
+                }
+            }
+            return yield(n)
+        }
+        visit(root)
+    }
+}
+
+// BFS is like DFS, but it traverses the tree in breadth-first order, so nodes are yielded level by level.
+func BFS[N any](root N, children func(N) Iterator[N], skip ...func(N) bool) Iterator[N] {
+    return func(yield func(N) bool) {
+        firstVisit := func(N) bool { return true }
+        for _, n := range bfs(root, children, traversalSkipper(skip), firstVisit) {
+            if !yield(n) {
+                return
+            }
+        }
+    }
+}
+
+// GraphDFS traverses a graph in depth-first pre-order starting from root, and yields 2-tuples of (depth, node), where the depth of root is 0.
+// Each node is yielded at most once, nodes that have been visited are not expanded again, so it is safe for graphs with cycles.
+// The optional skip predicate works the same as in DFS.
+// For example:
+//
+//  // all the modules that "app" depends on, directly or indirectly
+//  for depth, module := range goiter.GraphDFS("app", deps) {
+//      fmt.Printf("%s%s\n", strings.Repeat("  ", depth), module)
+//  }
+func GraphDFS[N comparable](root N, neighbors func(N) Iterator[N], skip ...func(N) bool) Iterator2[int, N] {
+    return func(yield func(int, N) bool) {
+        shouldSkip := traversalSkipper(skip)
+        visited := map[N]bool{}
+        var visit func(n N, depth int) bool
+        visit = func(n N, depth int) bool {
+            if visited[n] || shouldSkip(n) {
+                return true
+            }
+            visited[n] = true
+            if !yield(depth, n) {
+                return false
+            }
+            for c := range neighbors(n) {
+                if !visit(c, depth+1) {
+                    return false
+                }
+            }
+            return true
+        }
+        visit(root, 0)
+    }
+}
+
+// GraphBFS is like GraphDFS, but it traverses the graph in breadth-first order, so the depth of each node is its shortest distance from root.
+func GraphBFS[N comparable](root N, neighbors func(N) Iterator[N], skip ...func(N) bool) Iterator2[int, N] {
+    return func(yield func(int, N) bool) {
+        visited := map[N]bool{}
+        firstVisit := func(n N) bool {
+            if visited[n] {
+                return false
+            }
+            visited[n] = true
+            return true
+        }
+        for depth, n := range bfs(root, neighbors, traversalSkipper(skip), firstVisit) {
+            if !yield(depth, n) {
+                return
+            }
+        }
+    }
+}
+
+// bfs only enqueues a node if firstVisit returns true for it, which is how graph traversals deduplicate nodes.
+func bfs[N any](root N, children func(N) Iterator[N], shouldSkip func(N) bool, firstVisit func(N) bool) Iterator2[int, N] {
+    return func(yield func(int, N) bool) {
+        if shouldSkip(root) || !firstVisit(root) {
+            return
+        }
+        queue := []Combined[int, N]{{V1: 0, V2: root}}
+        for len(queue) > 0 {
+            curr := queue[0]
+            queue[0] = Combined[int, N]{}
+            queue = queue[1:]
+            if !yield(curr.V1, curr.V2) {
+                return
+            }
+            for c := range children(curr.V2) {
+                if shouldSkip(c) || !firstVisit(c) {
+                    continue
+                }
+                queue = append(queue, Combined[int, N]{V1: curr.V1 + 1, V2: c})
+            }
+        }
+    }
+}
+
+func traversalSkipper[N any](skip []func(N) bool) func(N) bool {
+    if len(skip) == 0 || skip[0] == nil {
+        return func(N) bool { return false }
+    }
+    return skip[0]
+}
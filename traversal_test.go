@@ -0,0 +1,145 @@
+package goiter
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+    "testing"
+)
+
+type testTreeNode struct {
+    name     string
+    children []*testTreeNode
+}
+
+func newTestTree() *testTreeNode {
+    //        a
+    //      / | \
+    //     b  c  d
+    //    / \    |
+    //   e   f   g
+    leaf := func(name string) *testTreeNode { return &testTreeNode{name: name} }
+    return &testTreeNode{name: "a", children: []*testTreeNode{
+        {name: "b", children: []*testTreeNode{leaf("e"), leaf("f")}},
+        leaf("c"),
+        {name: "d", children: []*testTreeNode{leaf("g")}},
+    }}
+}
+
+func testTreeChildren(n *testTreeNode) Iterator[*testTreeNode] {
+    return SliceElems(n.children)
+}
+
+func testTreeNames(it Iterator[*testTreeNode]) string {
+    names := []string{}
+    for n := range it {
+        names = append(names, n.name)
+    }
+    return strings.Join(names, "")
+}
+
+func TestDFS(t *testing.T) {
+    tree := newTestTree()
+    if actual := testTreeNames(DFS(tree, testTreeChildren)); actual != "abefcdg" {
+        t.Fatal(fmt.Sprintf("expect: abefcdg, actual: %s", actual))
+    }
+
+    skipB := func(n *testTreeNode) bool { return n.name == "b" }
+    if actual := testTreeNames(DFS(tree, testTreeChildren, skipB)); actual != "acdg" {
+        t.Fatal(fmt.Sprintf("expect: acdg, actual: %s", actual))
+    }
+
+    // works with other operators
+    if actual := testTreeNames(DFS(tree, testTreeChildren).Take(3)); actual != "abe" {
+        t.Fatal(fmt.Sprintf("expect: abe, actual: %s", actual))
+    }
+}
+
+func TestPostOrder(t *testing.T) {
+    tree := newTestTree()
+    if actual := testTreeNames(PostOrder(tree, testTreeChildren)); actual != "efbcgda" {
+        t.Fatal(fmt.Sprintf("expect: efbcgda, actual: %s", actual))
+    }
+
+    skipD := func(n *testTreeNode) bool { return n.name == "d" }
+    if actual := testTreeNames(PostOrder(tree, testTreeChildren, skipD)); actual != "efbca" {
+        t.Fatal(fmt.Sprintf("expect: efbca, actual: %s", actual))
+    }
+
+    if actual := testTreeNames(PostOrder(tree, testTreeChildren).Take(2)); actual != "ef" {
+        t.Fatal(fmt.Sprintf("expect: ef, actual: %s", actual))
+    }
+}
+
+func TestBFS(t *testing.T) {
+    tree := newTestTree()
+    if actual := testTreeNames(BFS(tree, testTreeChildren)); actual != "abcdefg" {
+        t.Fatal(fmt.Sprintf("expect: abcdefg, actual: %s", actual))
+    }
+
+    skipB := func(n *testTreeNode) bool { return n.name == "b" }
+    if actual := testTreeNames(BFS(tree, testTreeChildren, skipB)); actual != "acdg" {
+        t.Fatal(fmt.Sprintf("expect: acdg, actual: %s", actual))
+    }
+
+    skipAll := func(n *testTreeNode) bool { return true }
+    if actual := testTreeNames(BFS(tree, testTreeChildren, skipAll)); actual != "" {
+        t.Fatal(fmt.Sprintf("expect nothing, actual: %s", actual))
+    }
+
+    if actual := testTreeNames(BFS(tree, testTreeChildren).Take(2)); actual != "ab" {
+        t.Fatal(fmt.Sprintf("expect: ab, actual: %s", actual))
+    }
+}
+
+func TestGraphTraversal(t *testing.T) {
+    // a -> b -> d -> a (cycle)
+    // a -> c -> d
+    graph := map[string][]string{
+        "a": {"b", "c"},
+        "b": {"d"},
+        "c": {"d"},
+        "d": {"a"},
+    }
+    neighbors := func(n string) Iterator[string] {
+        return SliceElems(graph[n])
+    }
+    collect := func(it Iterator2[int, string]) ([]int, []string) {
+        depths := []int{}
+        nodes := []string{}
+        for depth, n := range it {
+            depths = append(depths, depth)
+            nodes = append(nodes, n)
+        }
+        return depths, nodes
+    }
+
+    depths, nodes := collect(GraphDFS("a", neighbors))
+    if !slices.Equal([]string{"a", "b", "d", "c"}, nodes) || !slices.Equal([]int{0, 1, 2, 1}, depths) {
+        t.Fatal(fmt.Sprintf("unexpected result: %v %v", nodes, depths))
+    }
+
+    depths, nodes = collect(GraphBFS("a", neighbors))
+    if !slices.Equal([]string{"a", "b", "c", "d"}, nodes) || !slices.Equal([]int{0, 1, 1, 2}, depths) {
+        t.Fatal(fmt.Sprintf("unexpected result: %v %v", nodes, depths))
+    }
+
+    skipB := func(n string) bool { return n == "b" }
+    depths, nodes = collect(GraphDFS("a", neighbors, skipB))
+    if !slices.Equal([]string{"a", "c", "d"}, nodes) || !slices.Equal([]int{0, 1, 2}, depths) {
+        t.Fatal(fmt.Sprintf("unexpected result: %v %v", nodes, depths))
+    }
+    depths, nodes = collect(GraphBFS("a", neighbors, skipB))
+    if !slices.Equal([]string{"a", "c", "d"}, nodes) || !slices.Equal([]int{0, 1, 2}, depths) {
+        t.Fatal(fmt.Sprintf("unexpected result: %v %v", nodes, depths))
+    }
+
+    _, nodes = collect(GraphDFS("a", neighbors).Take(2))
+    if !slices.Equal([]string{"a", "b"}, nodes) {
+        t.Fatal(fmt.Sprintf("unexpected result: %v", nodes))
+    }
+    _, nodes = collect(GraphBFS("a", neighbors).Take(2))
+    if !slices.Equal([]string{"a", "b"}, nodes) {
+        t.Fatal(fmt.Sprintf("unexpected result: %v", nodes))
+    }
+}
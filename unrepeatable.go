@@ -16,14 +16,7 @@ func Once[TIter SeqX[T], T any](iterator TIter) Iterator[T] {
             return
         }
 
-        next, stop := iter.Pull(iter.Seq[T](iterator))
-        defer stop()
-
-        for {
-            v, ok := next()
-            if !ok {
-                break
-            }
+        for v := range iterator {
             if !yield(v) {
                 break
             }
@@ -39,14 +32,7 @@ func Once2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter) Iterator2[T1, T2] {
             return
         }
 
-        next, stop := iter.Pull2(iter.Seq2[T1, T2](iterator))
-        defer stop()
-
-        for {
-            v1, v2, ok := next()
-            if !ok {
-                break
-            }
+        for v1, v2 := range iterator {
             if !yield(v1, v2) {
                 break
             }
@@ -77,7 +63,7 @@ func Once2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter) Iterator2[T1, T2] {
 //  }
 func FinishOnce[TIter SeqX[T], T any](iterator TIter) Iterator[T] {
     fetchLock := &sync.Mutex{}
-    next, stop := iter.Pull(iter.Seq[T](Once(iterator)))
+    next, stop := pull(iter.Seq[T](Once(iterator)))
     stopFunc := sync.OnceFunc(stop)
     nextFunc := func() (T, bool) {
         fetchLock.Lock()
@@ -104,7 +90,7 @@ func FinishOnce[TIter SeqX[T], T any](iterator TIter) Iterator[T] {
 // FinishOnce2 is the iter.Seq2 version of FinishOnce function.
 func FinishOnce2[TIter Seq2X[T1, T2], T1, T2 any](iterator TIter) Iterator2[T1, T2] {
     fetchLock := &sync.Mutex{}
-    next, stop := iter.Pull2(iter.Seq2[T1, T2](Once2(iterator)))
+    next, stop := pull2(iter.Seq2[T1, T2](Once2(iterator)))
     stopFunc := sync.OnceFunc(stop)
     nextFunc := func() (T1, T2, bool) {
         fetchLock.Lock()